@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tanq16/linksnapper/internal/server"
+)
+
+var reportFlags struct {
+	data            string
+	json            bool
+	includeDegraded bool
+}
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Print reports about stored links",
+}
+
+var deadLinksCmd = &cobra.Command{
+	Use:   "dead-links",
+	Short: "Print dead links grouped by host and error type",
+	Run: func(cmd *cobra.Command, args []string) {
+		links, err := server.LoadLinks(reportFlags.data)
+		if err != nil {
+			fatal("failed to load links", "data", reportFlags.data, "error", err)
+		}
+		report := server.BuildDeadLinkReport(links, reportFlags.includeDegraded)
+		if reportFlags.json {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(report); err != nil {
+				fatal("failed to encode report", "error", err)
+			}
+			return
+		}
+		if report.Total == 0 {
+			fmt.Println("No dead links found.")
+			return
+		}
+		fmt.Printf("%d dead links across %d hosts\n", report.Total, len(report.Hosts))
+		for _, host := range report.Hosts {
+			fmt.Printf("\n%s (%d)\n", host.Host, host.Total)
+			for _, group := range host.Groups {
+				fmt.Printf("  %s (%d)\n", group.Type, len(group.Links))
+				for _, link := range group.Links {
+					detail := link.Health.Error
+					if detail == "" && link.Health.StatusCode != 0 {
+						detail = fmt.Sprintf("HTTP %d", link.Health.StatusCode)
+					}
+					fmt.Printf("    %s  %s\n", link.URL, detail)
+				}
+			}
+		}
+	},
+}
+
+func init() {
+	deadLinksCmd.Flags().StringVarP(&reportFlags.data, "data", "d", "data", "Data directory for storage")
+	deadLinksCmd.Flags().BoolVar(&reportFlags.json, "json", false, "Print the report as JSON")
+	deadLinksCmd.Flags().BoolVar(&reportFlags.includeDegraded, "include-degraded", false, "Include links that are failing but not yet dead")
+	reportCmd.AddCommand(deadLinksCmd)
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDeadLinksReportLeavesDataUntouched(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "links.json")
+	// Saved before timestamps were tracked, which NewStore would backfill
+	// and write back.
+	original := []byte(`[{"id":"a","url":"https://gone.example/","path":["Reading"],"health":{"status":"unhealthy","state":"dead","statusCode":404},"lastChecked":"2026-09-01T00:00:00Z"}]`)
+	if err := os.WriteFile(file, original, 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	reportFlags.data = dir
+	t.Cleanup(func() { reportFlags.data = "data" })
+
+	deadLinksCmd.Run(deadLinksCmd, nil)
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if !bytes.Equal(data, original) {
+		t.Fatalf("links.json after report = %s, want it unchanged", data)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("data directory after report has %d entries, want only links.json", len(entries))
+	}
+}
@@ -2,13 +2,20 @@ package cmd
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 
 	"github.com/spf13/cobra"
+	"github.com/tanq16/linksnapper/internal/server"
 )
 
 var AppVersion = "dev-build"
 
+var logFlags struct {
+	level  string
+	format string
+}
+
 var rootCmd = &cobra.Command{
 	Use:     "linksnapper",
 	Short:   "A self-hosted bookmark manager",
@@ -16,6 +23,19 @@ var rootCmd = &cobra.Command{
 	CompletionOptions: cobra.CompletionOptions{
 		HiddenDefaultCmd: true,
 	},
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		logger, err := server.NewLogger(os.Stderr, logFlags.level, logFlags.format)
+		if err != nil {
+			return err
+		}
+		slog.SetDefault(logger)
+		return nil
+	},
+}
+
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
 }
 
 func Execute() {
@@ -26,6 +46,9 @@ func Execute() {
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVar(&logFlags.level, "log-level", "info", "Log level (debug, info, warn, error)")
+	rootCmd.PersistentFlags().StringVar(&logFlags.format, "log-format", "text", "Log format (text or json)")
 	rootCmd.SetHelpCommand(&cobra.Command{Hidden: true})
 	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(reportCmd)
 }
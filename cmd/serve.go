@@ -2,9 +2,10 @@ package cmd
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
@@ -24,20 +25,27 @@ var serveCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		store, err := server.NewStore(serveFlags.data)
 		if err != nil {
-			log.Fatalf("ERROR Failed to initialize store: %v", err)
+			fatal("failed to initialize store", "data", serveFlags.data, "error", err)
 		}
 
-		srv := server.New(serveFlags.host, serveFlags.port, store, serveFlags.data)
-		if err := srv.Setup(); err != nil {
-			log.Fatalf("ERROR Failed to setup server: %v", err)
+		settings, err := server.LoadSettings(serveFlags.data)
+		if err != nil {
+			fatal("failed to load settings", "error", err)
 		}
+		notifier := server.NewNotifier(store.Events(), settings.Webhooks, filepath.Join(serveFlags.data, "webhooks-dead-letter.jsonl"))
+		notifier.Start()
 
 		healthChecker := server.NewHealthChecker(store, 48*time.Hour)
+		srv := server.New(serveFlags.host, serveFlags.port, store, healthChecker, serveFlags.data)
+		srv.SetURLNormalization(settings.URLNormalization)
+		if err := srv.Setup(); err != nil {
+			fatal("failed to set up server", "error", err)
+		}
 		healthChecker.Start()
 
 		errCh := make(chan error, 1)
 		go func() {
-			log.Printf("INFO Starting server on %s:%d", serveFlags.host, serveFlags.port)
+			slog.Info("starting server", "host", serveFlags.host, "port", serveFlags.port)
 			if err := srv.Run(); err != nil {
 				errCh <- err
 			}
@@ -48,18 +56,19 @@ var serveCmd = &cobra.Command{
 
 		select {
 		case err := <-errCh:
-			log.Fatalf("ERROR Server failed to start: %v", err)
+			fatal("server failed to start", "error", err)
 		case <-quit:
 		}
 
-		log.Printf("INFO Shutting down server...")
+		slog.Info("shutting down server")
 		healthChecker.Stop()
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 		if err := srv.Shutdown(ctx); err != nil {
-			log.Fatalf("ERROR Server forced to shutdown: %v", err)
+			fatal("server forced to shut down", "error", err)
 		}
-		log.Printf("INFO Server stopped gracefully")
+		notifier.Stop()
+		slog.Info("server stopped gracefully")
 	},
 }
 
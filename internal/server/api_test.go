@@ -0,0 +1,73 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAPIRoutesAndErrors(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	if _, err := store.AddLink(Link{ID: "existing", URL: "https://dup.example", Path: []string{"Tech"}}); err != nil {
+		t.Fatalf("AddLink() error = %v", err)
+	}
+	srv := New("", 0, store, NewHealthChecker(store, time.Hour), t.TempDir())
+	if err := srv.Setup(); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantCode   string
+		wantAllow  string
+	}{
+		{name: "v1 list", method: http.MethodGet, path: "/api/v1/links", wantStatus: http.StatusOK},
+		{name: "legacy alias", method: http.MethodGet, path: "/api/links", wantStatus: http.StatusOK},
+		{name: "v1 link health", method: http.MethodGet, path: "/api/v1/links/existing/health", wantStatus: http.StatusOK},
+		{name: "duplicate link", method: http.MethodPost, path: "/api/v1/links", body: `{"url":"https://dup.example"}`, wantStatus: http.StatusConflict, wantCode: "link_exists"},
+		{name: "legacy duplicate link", method: http.MethodPost, path: "/api/links", body: `{"url":"https://dup.example"}`, wantStatus: http.StatusConflict, wantCode: "link_exists"},
+		{name: "invalid json", method: http.MethodPost, path: "/api/v1/links", body: `{`, wantStatus: http.StatusBadRequest, wantCode: "invalid_json"},
+		{name: "missing link", method: http.MethodDelete, path: "/api/v1/links/missing", wantStatus: http.StatusNotFound, wantCode: "link_not_found"},
+		{name: "missing bookmark", method: http.MethodDelete, path: "/api/v1/bookmarks/missing", wantStatus: http.StatusNotFound, wantCode: "bookmark_not_found"},
+		{name: "missing job", method: http.MethodGet, path: "/api/v1/health/jobs/missing", wantStatus: http.StatusNotFound, wantCode: "job_not_found"},
+		{name: "wrong method", method: http.MethodPatch, path: "/api/v1/links", wantStatus: http.StatusMethodNotAllowed, wantCode: "method_not_allowed", wantAllow: "GET, POST"},
+		{name: "unknown route", method: http.MethodGet, path: "/api/v1/nope", wantStatus: http.StatusNotFound, wantCode: "not_found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			srv.httpServer.Handler.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("%s %s status = %d, want %d: %s", tt.method, tt.path, rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantAllow != "" && rec.Header().Get("Allow") != tt.wantAllow {
+				t.Fatalf("Allow = %q, want %q", rec.Header().Get("Allow"), tt.wantAllow)
+			}
+			if tt.wantCode == "" {
+				return
+			}
+			var envelope errorEnvelope
+			if err := json.NewDecoder(rec.Body).Decode(&envelope); err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if envelope.Error.Code != tt.wantCode || envelope.Error.Message == "" {
+				t.Fatalf("error = %#v, want code %q", envelope.Error, tt.wantCode)
+			}
+			if envelope.Error.RequestID == "" || envelope.Error.RequestID != rec.Header().Get(requestIDHeader) {
+				t.Fatalf("requestId = %q, want %q", envelope.Error.RequestID, rec.Header().Get(requestIDHeader))
+			}
+		})
+	}
+}
@@ -0,0 +1,363 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// BookmarkFolderInfo describes a category or folder without its contents.
+type BookmarkFolderInfo struct {
+	Path      string `json:"path"`
+	Name      string `json:"name"`
+	Color     string `json:"color,omitempty"`
+	Icon      string `json:"icon,omitempty"`
+	Folded    bool   `json:"folded,omitempty"`
+	Pinned    bool   `json:"pinned,omitempty"`
+	Bookmarks int    `json:"bookmarks"`
+	Total     int    `json:"total"`
+}
+
+// BookmarkFolderInput creates a category or folder. Explicitly created
+// containers are pinned unless Pinned is false, so they survive while empty.
+type BookmarkFolderInput struct {
+	Path   string `json:"path"`
+	Color  string `json:"color"`
+	Icon   string `json:"icon"`
+	Folded bool   `json:"folded"`
+	Pinned *bool  `json:"pinned"`
+}
+
+// BookmarkFolderPatch changes the fields that are set. Name renames the
+// container in place and Parent moves it, with "" meaning the top level.
+type BookmarkFolderPatch struct {
+	Name   *string `json:"name"`
+	Parent *string `json:"parent"`
+	Color  *string `json:"color"`
+	Icon   *string `json:"icon"`
+	Folded *bool   `json:"folded"`
+	Pinned *bool   `json:"pinned"`
+}
+
+// Folder delete modes: cascade removes the contents with the container, move
+// hands them to the parent (or to Uncategorized for a category).
+const (
+	folderDeleteCascade = "cascade"
+	folderDeleteMove    = "move"
+)
+
+func (c BookmarkCategory) folder() BookmarkFolder {
+	return BookmarkFolder{Name: c.Category, Color: c.Color, Icon: c.Icon, Folded: c.Folded, Pinned: c.Pinned, Links: c.Links, Folders: c.Folders}
+}
+
+func (f BookmarkFolder) category() BookmarkCategory {
+	return BookmarkCategory{Category: f.Name, Color: f.Color, Icon: f.Icon, Folded: f.Folded, Pinned: f.Pinned, Links: f.Links, Folders: f.Folders}
+}
+
+func (f BookmarkFolder) info(path []string) BookmarkFolderInfo {
+	total := len(f.Links)
+	forEachFolderBookmark(f.Folders, nil, func([]string, *BookmarkLink) { total++ })
+	return BookmarkFolderInfo{
+		Path:      strings.Join(path, "/"),
+		Name:      f.Name,
+		Color:     f.Color,
+		Icon:      f.Icon,
+		Folded:    f.Folded,
+		Pinned:    f.Pinned,
+		Bookmarks: len(f.Links),
+		Total:     total,
+	}
+}
+
+// Folders lists every category and folder, parents before their children.
+func (s *BookmarkStore) Folders() ([]BookmarkFolderInfo, error) {
+	config, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	infos := []BookmarkFolderInfo{}
+	var visit func(folders []BookmarkFolder, parent []string)
+	visit = func(folders []BookmarkFolder, parent []string) {
+		for _, folder := range folders {
+			path := append(slices.Clip(parent), folder.Name)
+			infos = append(infos, folder.info(path))
+			visit(folder.Folders, path)
+		}
+	}
+	for _, category := range config.Bookmarks {
+		visit([]BookmarkFolder{category.folder()}, nil)
+	}
+	return infos, nil
+}
+
+func (s *BookmarkStore) CreateFolder(input BookmarkFolderInput) (BookmarkFolderInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path, err := parseBookmarkPath(input.Path)
+	if err != nil {
+		return BookmarkFolderInfo{}, invalidRequest(err.Error())
+	}
+	config, _, err := s.loadNormalized()
+	if err != nil {
+		return BookmarkFolderInfo{}, err
+	}
+	folder := BookmarkFolder{
+		Name:   path[len(path)-1],
+		Color:  normalizeBookmarkColor(input.Color),
+		Icon:   input.Icon,
+		Folded: input.Folded,
+		Pinned: input.Pinned == nil || *input.Pinned,
+	}
+	parent := path[:len(path)-1]
+	if len(parent) > 0 {
+		// Missing parents are created like they are for a new bookmark.
+		bookmarkLinksAt(&config, parent, BookmarkCategory{})
+	}
+	if err := insertBookmarkFolder(&config, parent, folder, -1); err != nil {
+		return BookmarkFolderInfo{}, err
+	}
+	pruneBookmarks(&config)
+	if err := s.save(config); err != nil {
+		return BookmarkFolderInfo{}, err
+	}
+	s.events.Publish(EventBookmarkFolderChanged, BookmarkFolderEvent{Action: "created", Path: strings.Join(path, "/")})
+	return folder.info(path), nil
+}
+
+func (s *BookmarkStore) UpdateFolder(folderPath string, patch BookmarkFolderPatch) (BookmarkFolderInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path, err := parseBookmarkPath(folderPath)
+	if err != nil {
+		return BookmarkFolderInfo{}, invalidRequest(err.Error())
+	}
+	config, _, err := s.loadNormalized()
+	if err != nil {
+		return BookmarkFolderInfo{}, err
+	}
+	folder, index, ok := takeBookmarkFolder(&config, path)
+	if !ok {
+		return BookmarkFolderInfo{}, ErrFolderNotFound
+	}
+	parent := path[:len(path)-1]
+	if patch.Parent != nil {
+		if *patch.Parent == "" {
+			parent = nil
+		} else if parent, err = parseBookmarkPath(*patch.Parent); err != nil {
+			return BookmarkFolderInfo{}, invalidRequest(err.Error())
+		}
+		if hasPathPrefix(parent, path) {
+			return BookmarkFolderInfo{}, invalidRequest("a folder cannot be moved into itself")
+		}
+		if len(parent) > 0 && bookmarkFoldersAt(&config, parent) == nil {
+			return BookmarkFolderInfo{}, fmt.Errorf("parent %s: %w", *patch.Parent, ErrFolderNotFound)
+		}
+		if !slices.Equal(parent, path[:len(path)-1]) {
+			index = -1
+		}
+	}
+	if patch.Name != nil {
+		folder.Name = strings.TrimSpace(*patch.Name)
+	}
+	if patch.Color != nil {
+		folder.Color = normalizeBookmarkColor(*patch.Color)
+	}
+	if patch.Icon != nil {
+		folder.Icon = *patch.Icon
+	}
+	if patch.Folded != nil {
+		folder.Folded = *patch.Folded
+	}
+	if patch.Pinned != nil {
+		folder.Pinned = *patch.Pinned
+	}
+	if err := insertBookmarkFolder(&config, parent, folder, index); err != nil {
+		return BookmarkFolderInfo{}, err
+	}
+	pruneBookmarks(&config)
+	if err := s.save(config); err != nil {
+		return BookmarkFolderInfo{}, err
+	}
+	newPath := append(slices.Clone(parent), folder.Name)
+	s.events.Publish(EventBookmarkFolderChanged, BookmarkFolderEvent{Action: "updated", Path: strings.Join(path, "/"), NewPath: strings.Join(newPath, "/")})
+	return folder.info(newPath), nil
+}
+
+func (s *BookmarkStore) DeleteFolder(folderPath, mode string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path, err := parseBookmarkPath(folderPath)
+	if err != nil {
+		return invalidRequest(err.Error())
+	}
+	config, _, err := s.loadNormalized()
+	if err != nil {
+		return err
+	}
+	folder, _, ok := takeBookmarkFolder(&config, path)
+	if !ok {
+		return ErrFolderNotFound
+	}
+	empty := len(folder.Links) == 0 && len(folder.Folders) == 0
+	switch {
+	case mode == folderDeleteMove && !empty:
+		target := path[:len(path)-1]
+		if len(target) == 0 {
+			target = []string{"Uncategorized"}
+		}
+		links := bookmarkLinksAt(&config, target, BookmarkCategory{})
+		*links = append(*links, folder.Links...)
+		folders := bookmarkFoldersAt(&config, target)
+		*folders = mergeBookmarkFolders(*folders, folder.Folders)
+	case mode == "" && !empty:
+		return ErrFolderNotEmpty
+	}
+	pruneBookmarks(&config)
+	if err := s.save(config); err != nil {
+		return err
+	}
+	s.events.Publish(EventBookmarkFolderChanged, BookmarkFolderEvent{Action: "deleted", Path: strings.Join(path, "/")})
+	return nil
+}
+
+// takeBookmarkFolder removes the category or folder at path and returns it
+// with its index among its siblings.
+func takeBookmarkFolder(config *BookmarkConfig, path []string) (BookmarkFolder, int, bool) {
+	name := path[len(path)-1]
+	if len(path) == 1 {
+		index := slices.IndexFunc(config.Bookmarks, func(category BookmarkCategory) bool {
+			return category.Category == name
+		})
+		if index == -1 {
+			return BookmarkFolder{}, -1, false
+		}
+		folder := config.Bookmarks[index].folder()
+		config.Bookmarks = slices.Delete(config.Bookmarks, index, index+1)
+		return folder, index, true
+	}
+	folders := bookmarkFoldersAt(config, path[:len(path)-1])
+	if folders == nil {
+		return BookmarkFolder{}, -1, false
+	}
+	index := slices.IndexFunc(*folders, func(folder BookmarkFolder) bool {
+		return folder.Name == name
+	})
+	if index == -1 {
+		return BookmarkFolder{}, -1, false
+	}
+	folder := (*folders)[index]
+	*folders = slices.Delete(*folders, index, index+1)
+	return folder, index, true
+}
+
+// insertBookmarkFolder adds folder under parent at index, or at the end when
+// index is negative. At the top level it becomes a category.
+func insertBookmarkFolder(config *BookmarkConfig, parent []string, folder BookmarkFolder, index int) error {
+	if folder.Name == "" || strings.Contains(folder.Name, "/") {
+		return invalidRequest("folder names must be non-empty and must not contain /")
+	}
+	if len(parent) == 0 {
+		if slices.ContainsFunc(config.Bookmarks, func(category BookmarkCategory) bool {
+			return category.Category == folder.Name
+		}) {
+			return ErrFolderExists
+		}
+		if index < 0 || index > len(config.Bookmarks) {
+			index = len(config.Bookmarks)
+		}
+		config.Bookmarks = slices.Insert(config.Bookmarks, index, folder.category())
+		return nil
+	}
+	folders := bookmarkFoldersAt(config, parent)
+	if folders == nil {
+		return ErrFolderNotFound
+	}
+	if slices.ContainsFunc(*folders, func(existing BookmarkFolder) bool {
+		return existing.Name == folder.Name
+	}) {
+		return ErrFolderExists
+	}
+	if index < 0 || index > len(*folders) {
+		index = len(*folders)
+	}
+	*folders = slices.Insert(*folders, index, folder)
+	return nil
+}
+
+// mergeBookmarkFolders adds folders to dst, combining the contents of folders
+// that have the same name.
+func mergeBookmarkFolders(dst, folders []BookmarkFolder) []BookmarkFolder {
+	for _, folder := range folders {
+		index := slices.IndexFunc(dst, func(existing BookmarkFolder) bool {
+			return existing.Name == folder.Name
+		})
+		if index == -1 {
+			dst = append(dst, folder)
+			continue
+		}
+		dst[index].Links = append(dst[index].Links, folder.Links...)
+		dst[index].Folders = mergeBookmarkFolders(dst[index].Folders, folder.Folders)
+	}
+	return dst
+}
+
+func (s *Server) handleListBookmarkFolders(w http.ResponseWriter, r *http.Request) {
+	folders, err := s.bookmarks.Folders()
+	if err != nil {
+		writeError(w, r, fmt.Errorf("list bookmark folders: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, folders)
+}
+
+func (s *Server) handleCreateBookmarkFolder(w http.ResponseWriter, r *http.Request) {
+	var input BookmarkFolderInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, r, ErrInvalidJSON)
+		return
+	}
+	if strings.TrimSpace(input.Path) == "" {
+		writeError(w, r, invalidRequest("path is required"))
+		return
+	}
+	folder, err := s.bookmarks.CreateFolder(input)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("create bookmark folder: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusCreated, folder)
+}
+
+func (s *Server) handleUpdateBookmarkFolder(w http.ResponseWriter, r *http.Request) {
+	path := r.PathValue("path")
+	var patch BookmarkFolderPatch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		writeError(w, r, ErrInvalidJSON)
+		return
+	}
+	folder, err := s.bookmarks.UpdateFolder(path, patch)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("update bookmark folder %s: %w", path, err))
+		return
+	}
+	writeJSON(w, http.StatusOK, folder)
+}
+
+func (s *Server) handleDeleteBookmarkFolder(w http.ResponseWriter, r *http.Request) {
+	path := r.PathValue("path")
+	mode := r.URL.Query().Get("mode")
+	if mode != "" && mode != folderDeleteCascade && mode != folderDeleteMove {
+		writeError(w, r, invalidRequest("mode must be cascade or move"))
+		return
+	}
+	if err := s.bookmarks.DeleteFolder(path, mode); err != nil {
+		writeError(w, r, fmt.Errorf("delete bookmark folder %s: %w", path, err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
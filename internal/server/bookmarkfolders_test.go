@@ -0,0 +1,205 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func folderPaths(t *testing.T, store *BookmarkStore) []string {
+	t.Helper()
+	folders, err := store.Folders()
+	if err != nil {
+		t.Fatalf("Folders() error = %v", err)
+	}
+	var paths []string
+	for _, folder := range folders {
+		paths = append(paths, folder.Path)
+	}
+	return paths
+}
+
+func TestBookmarkFolderManagement(t *testing.T) {
+	str := func(value string) *string { return &value }
+	flag := func(value bool) *bool { return &value }
+	tests := []struct {
+		name     string
+		run      func(store *BookmarkStore) error
+		want     []string
+		folders  []string
+		wantCode string
+	}{
+		{
+			name: "create pinned stays while empty",
+			run: func(store *BookmarkStore) error {
+				_, err := store.CreateFolder(BookmarkFolderInput{Path: "Home/Media/Films"})
+				return err
+			},
+			want:    []string{"Work:a", "Work:b", "Home/Infra:c"},
+			folders: []string{"Work", "Home", "Home/Infra", "Home/Media", "Home/Media/Films"},
+		},
+		{
+			name: "create existing",
+			run: func(store *BookmarkStore) error {
+				_, err := store.CreateFolder(BookmarkFolderInput{Path: "Home/Infra"})
+				return err
+			},
+			wantCode: "folder_exists",
+		},
+		{
+			name: "rename keeps position",
+			run: func(store *BookmarkStore) error {
+				_, err := store.UpdateFolder("Work", BookmarkFolderPatch{Name: str("Office"), Color: str("red")})
+				return err
+			},
+			want:    []string{"Office:a", "Office:b", "Home/Infra:c"},
+			folders: []string{"Office", "Home", "Home/Infra"},
+		},
+		{
+			name: "move folder to another category",
+			run: func(store *BookmarkStore) error {
+				_, err := store.UpdateFolder("Home/Infra", BookmarkFolderPatch{Parent: str("Work")})
+				return err
+			},
+			want:    []string{"Work:a", "Work:b", "Work/Infra:c"},
+			folders: []string{"Work", "Work/Infra"},
+		},
+		{
+			name: "promote folder to category",
+			run: func(store *BookmarkStore) error {
+				_, err := store.UpdateFolder("Home/Infra", BookmarkFolderPatch{Parent: str(""), Pinned: flag(true)})
+				return err
+			},
+			want:    []string{"Work:a", "Work:b", "Infra:c"},
+			folders: []string{"Work", "Infra"},
+		},
+		{
+			name: "move into itself",
+			run: func(store *BookmarkStore) error {
+				_, err := store.UpdateFolder("Home", BookmarkFolderPatch{Parent: str("Home/Infra")})
+				return err
+			},
+			wantCode: "invalid_request",
+		},
+		{
+			name: "rename onto sibling",
+			run: func(store *BookmarkStore) error {
+				_, err := store.UpdateFolder("Home", BookmarkFolderPatch{Name: str("Work")})
+				return err
+			},
+			wantCode: "folder_exists",
+		},
+		{
+			name:     "delete non-empty without mode",
+			run:      func(store *BookmarkStore) error { return store.DeleteFolder("Home", "") },
+			wantCode: "folder_not_empty",
+		},
+		{
+			name:    "delete cascade",
+			run:     func(store *BookmarkStore) error { return store.DeleteFolder("Home", folderDeleteCascade) },
+			want:    []string{"Work:a", "Work:b"},
+			folders: []string{"Work"},
+		},
+		{
+			name:    "delete moving children",
+			run:     func(store *BookmarkStore) error { return store.DeleteFolder("Home/Infra", folderDeleteMove) },
+			want:    []string{"Work:a", "Work:b", "Home:c"},
+			folders: []string{"Work", "Home"},
+		},
+		{
+			name:    "delete category moving children",
+			run:     func(store *BookmarkStore) error { return store.DeleteFolder("Work", folderDeleteMove) },
+			want:    []string{"Home/Infra:c", "Uncategorized:a", "Uncategorized:b"},
+			folders: []string{"Home", "Home/Infra", "Uncategorized"},
+		},
+		{
+			name:     "delete unknown",
+			run:      func(store *BookmarkStore) error { return store.DeleteFolder("Home/Media", "") },
+			wantCode: "folder_not_found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewBookmarkStore(t.TempDir())
+			for _, seed := range []struct{ folder, name string }{{"Work", "a"}, {"Work", "b"}, {"Home/Infra", "c"}} {
+				if _, err := store.Create(seed.folder, BookmarkLink{Name: seed.name, URL: "https://" + seed.name + ".example"}); err != nil {
+					t.Fatalf("Create() error = %v", err)
+				}
+			}
+			err := tt.run(store)
+			code := ""
+			if apiErr := (*APIError)(nil); errors.As(err, &apiErr) {
+				code = apiErr.Code
+			}
+			if (err != nil) != (tt.wantCode != "") || code != tt.wantCode {
+				t.Fatalf("error = %v, want code %q", err, tt.wantCode)
+			}
+			if tt.wantCode != "" {
+				return
+			}
+			config, err := store.Load()
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if got := bookmarkNames(config); !slices.Equal(got, tt.want) {
+				t.Fatalf("bookmarks = %v, want %v", got, tt.want)
+			}
+			if got := folderPaths(t, store); !slices.Equal(got, tt.folders) {
+				t.Fatalf("folders = %v, want %v", got, tt.folders)
+			}
+		})
+	}
+}
+
+func TestBookmarkFolderUnpinPrunes(t *testing.T) {
+	store := NewBookmarkStore(t.TempDir())
+	if _, err := store.CreateFolder(BookmarkFolderInput{Path: "Later", Icon: "clock"}); err != nil {
+		t.Fatalf("CreateFolder() error = %v", err)
+	}
+	if got := folderPaths(t, store); !slices.Equal(got, []string{"Later"}) {
+		t.Fatalf("folders = %v, want the pinned empty category", got)
+	}
+	pinned := false
+	if _, err := store.UpdateFolder("Later", BookmarkFolderPatch{Pinned: &pinned}); err != nil {
+		t.Fatalf("UpdateFolder() error = %v", err)
+	}
+	if got := folderPaths(t, store); len(got) != 0 {
+		t.Fatalf("folders = %v, want none after unpinning", got)
+	}
+}
+
+func TestBookmarkFolderRoutes(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	srv := New("", 0, store, NewHealthChecker(store, time.Hour), t.TempDir())
+	if err := srv.Setup(); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	do := func(method, target, body string) int {
+		rec := httptest.NewRecorder()
+		srv.httpServer.Handler.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return rec.Code
+	}
+	if code := do(http.MethodPost, "/api/v1/bookmarks/folders", `{"path":"Home/Infra"}`); code != http.StatusCreated {
+		t.Fatalf("POST folder status = %d, want 201", code)
+	}
+	if code := do(http.MethodPatch, "/api/v1/bookmarks/folders/Home/Infra", `{"folded":true}`); code != http.StatusOK {
+		t.Fatalf("PATCH nested folder status = %d, want 200", code)
+	}
+	if code := do(http.MethodDelete, "/api/v1/bookmarks/folders/Home?mode=all", ""); code != http.StatusBadRequest {
+		t.Fatalf("DELETE with bad mode status = %d, want 400", code)
+	}
+	if code := do(http.MethodDelete, "/api/v1/bookmarks/folders/Home", ""); code != http.StatusConflict {
+		t.Fatalf("DELETE non-empty status = %d, want 409", code)
+	}
+	if code := do(http.MethodDelete, "/api/v1/bookmarks/folders/Home?mode=cascade", ""); code != http.StatusNoContent {
+		t.Fatalf("DELETE cascade status = %d, want 204", code)
+	}
+}
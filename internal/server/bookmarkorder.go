@@ -0,0 +1,257 @@
+package server
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// BookmarkMove is one reorder operation. It moves either the bookmark ID or
+// the category or folder at Path. The destination is the position next to a
+// sibling named by Before or After (a bookmark ID, or a sibling folder path),
+// or Index within Folder for bookmarks and within the current parent for
+// folders. A missing Index means the end.
+type BookmarkMove struct {
+	ID     string `json:"id,omitempty"`
+	Path   string `json:"path,omitempty"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+	Folder string `json:"folder,omitempty"`
+	Index  *int   `json:"index,omitempty"`
+}
+
+func (m BookmarkMove) validate() error {
+	if (m.ID == "") == (m.Path == "") {
+		return invalidRequest("exactly one of id and path is required")
+	}
+	targets := 0
+	for _, set := range []bool{m.Before != "", m.After != "", m.Folder != "" || m.Index != nil} {
+		if set {
+			targets++
+		}
+	}
+	if targets != 1 {
+		return invalidRequest("exactly one of before, after or folder/index is required")
+	}
+	if m.Index != nil && *m.Index < 0 {
+		return invalidRequest("index must not be negative")
+	}
+	if m.ID != "" && m.Before == "" && m.After == "" && m.Folder == "" {
+		return invalidRequest("folder is required to move a bookmark to an index")
+	}
+	if m.Path != "" && m.Folder != "" {
+		return invalidRequest("folders can only be reordered within their parent")
+	}
+	for _, folderPath := range []string{m.Path, m.Folder} {
+		if folderPath == "" {
+			continue
+		}
+		if _, err := parseBookmarkPath(folderPath); err != nil {
+			return invalidRequest(err.Error())
+		}
+	}
+	return nil
+}
+
+// Move applies a reorder operation and saves the new order.
+func (s *BookmarkStore) Move(move BookmarkMove) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	config, _, err := s.loadNormalized()
+	if err != nil {
+		return err
+	}
+	if move.ID != "" {
+		err = moveBookmark(&config, move)
+	} else {
+		err = moveBookmarkFolder(&config, move)
+	}
+	if err != nil {
+		return err
+	}
+	pruneBookmarks(&config)
+	if err := s.save(config); err != nil {
+		return err
+	}
+	s.events.Publish(EventBookmarksReordered, move)
+	return nil
+}
+
+func moveBookmark(config *BookmarkConfig, move BookmarkMove) error {
+	if sibling := cmp.Or(move.Before, move.After); sibling == move.ID {
+		return invalidRequest("a bookmark cannot be moved next to itself")
+	}
+	link, ok := removeBookmarkID(config, move.ID)
+	if !ok {
+		return ErrBookmarkNotFound
+	}
+	// A move is a write to the bookmark, so that sync clients see it and an
+	// older push does not undo it.
+	link.UpdatedAt = time.Now().UTC()
+	if sibling := cmp.Or(move.Before, move.After); sibling != "" {
+		links, index, _ := findBookmark(config, sibling)
+		if links == nil {
+			return fmt.Errorf("sibling bookmark %s: %w", sibling, ErrBookmarkNotFound)
+		}
+		if move.After != "" {
+			index++
+		}
+		*links = slices.Insert(*links, index, link)
+		return nil
+	}
+	path, err := parseBookmarkPath(move.Folder)
+	if err != nil {
+		return invalidRequest(err.Error())
+	}
+	links := bookmarkLinksAt(config, path, BookmarkCategory{})
+	index := len(*links)
+	if move.Index != nil {
+		index = min(*move.Index, index)
+	}
+	*links = slices.Insert(*links, index, link)
+	return nil
+}
+
+func moveBookmarkFolder(config *BookmarkConfig, move BookmarkMove) error {
+	path, err := parseBookmarkPath(move.Path)
+	if err != nil {
+		return invalidRequest(err.Error())
+	}
+	parent, name := path[:len(path)-1], path[len(path)-1]
+	sibling := ""
+	if value := cmp.Or(move.Before, move.After); value != "" {
+		siblingPath, err := parseBookmarkPath(value)
+		if err != nil {
+			return invalidRequest(err.Error())
+		}
+		if !slices.Equal(siblingPath[:len(siblingPath)-1], parent) {
+			return invalidRequest("before and after must name a sibling of path")
+		}
+		sibling = siblingPath[len(siblingPath)-1]
+	}
+	after := move.After != ""
+	if len(parent) == 0 {
+		config.Bookmarks, err = moveNamed(config.Bookmarks, func(category BookmarkCategory) string {
+			return category.Category
+		}, name, sibling, after, move.Index)
+		return err
+	}
+	folders := bookmarkFoldersAt(config, parent)
+	if folders == nil {
+		return ErrFolderNotFound
+	}
+	*folders, err = moveNamed(*folders, func(folder BookmarkFolder) string {
+		return folder.Name
+	}, name, sibling, after, move.Index)
+	return err
+}
+
+// moveNamed moves the item called name next to sibling, or to index when no
+// sibling is given.
+func moveNamed[T any](items []T, nameOf func(T) string, name, sibling string, after bool, index *int) ([]T, error) {
+	named := func(target string) func(T) bool {
+		return func(item T) bool { return nameOf(item) == target }
+	}
+	from := slices.IndexFunc(items, named(name))
+	if from == -1 {
+		return nil, ErrFolderNotFound
+	}
+	item := items[from]
+	items = slices.Delete(items, from, from+1)
+	to := len(items)
+	if index != nil {
+		to = min(*index, to)
+	}
+	if sibling != "" {
+		to = slices.IndexFunc(items, named(sibling))
+		if to == -1 {
+			return nil, fmt.Errorf("sibling folder %s: %w", sibling, ErrFolderNotFound)
+		}
+		if after {
+			to++
+		}
+	}
+	return slices.Insert(items, to, item), nil
+}
+
+// findBookmark locates a bookmark: the list holding it, its index there and
+// the folder path of that list. The list is nil when the ID is unknown.
+func findBookmark(config *BookmarkConfig, id string) (*[]BookmarkLink, int, []string) {
+	var (
+		found *[]BookmarkLink
+		index int
+		path  []string
+	)
+	check := func(links *[]BookmarkLink, folder []string) bool {
+		i := slices.IndexFunc(*links, func(link BookmarkLink) bool { return link.ID == id })
+		if i == -1 {
+			return false
+		}
+		found, index, path = links, i, slices.Clone(folder)
+		return true
+	}
+	var search func(folders []BookmarkFolder, parent []string) bool
+	search = func(folders []BookmarkFolder, parent []string) bool {
+		for folderIndex := range folders {
+			folder := &folders[folderIndex]
+			folderPath := append(slices.Clip(parent), folder.Name)
+			if check(&folder.Links, folderPath) || search(folder.Folders, folderPath) {
+				return true
+			}
+		}
+		return false
+	}
+	for categoryIndex := range config.Bookmarks {
+		category := &config.Bookmarks[categoryIndex]
+		categoryPath := []string{category.Category}
+		if check(&category.Links, categoryPath) || search(category.Folders, categoryPath) {
+			break
+		}
+	}
+	return found, index, path
+}
+
+// bookmarkFoldersAt returns the sub-folder list of the category or folder at
+// path, or nil when it does not exist.
+func bookmarkFoldersAt(config *BookmarkConfig, path []string) *[]BookmarkFolder {
+	categoryIndex := slices.IndexFunc(config.Bookmarks, func(category BookmarkCategory) bool {
+		return category.Category == path[0]
+	})
+	if categoryIndex == -1 {
+		return nil
+	}
+	folders := &config.Bookmarks[categoryIndex].Folders
+	for _, name := range path[1:] {
+		folderIndex := slices.IndexFunc(*folders, func(folder BookmarkFolder) bool {
+			return folder.Name == name
+		})
+		if folderIndex == -1 {
+			return nil
+		}
+		folders = &(*folders)[folderIndex].Folders
+	}
+	return folders
+}
+
+func (s *Server) handleBookmarksReorder(w http.ResponseWriter, r *http.Request) {
+	var move BookmarkMove
+	if err := json.NewDecoder(r.Body).Decode(&move); err != nil {
+		writeError(w, r, ErrInvalidJSON)
+		return
+	}
+	move.Path, move.Folder = strings.TrimSpace(move.Path), strings.TrimSpace(move.Folder)
+	if err := move.validate(); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := s.bookmarks.Move(move); err != nil {
+		writeError(w, r, fmt.Errorf("reorder bookmarks: %w", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,142 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func bookmarkNames(config BookmarkConfig) []string {
+	var names []string
+	forEachBookmark(&config, func(path []string, link *BookmarkLink) {
+		names = append(names, strings.Join(path, "/")+":"+link.Name)
+	})
+	return names
+}
+
+func TestBookmarkUpdateKeepsPosition(t *testing.T) {
+	store := NewBookmarkStore(t.TempDir())
+	var ids []string
+	for _, name := range []string{"a", "b", "c"} {
+		link, err := store.Create("Work", BookmarkLink{Name: name, URL: "https://" + name + ".example"})
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		ids = append(ids, link.ID)
+	}
+	if _, err := store.Update(ids[0], "Work", BookmarkLink{Name: "A", URL: "https://a.example"}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	config, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got, want := bookmarkNames(config), []string{"Work:A", "Work:b", "Work:c"}; !slices.Equal(got, want) {
+		t.Fatalf("order after edit = %v, want %v", got, want)
+	}
+}
+
+func TestBookmarkMove(t *testing.T) {
+	index := func(value int) *int { return &value }
+	tests := []struct {
+		name    string
+		move    func(ids map[string]string) BookmarkMove
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "before sibling",
+			move: func(ids map[string]string) BookmarkMove { return BookmarkMove{ID: ids["c"], Before: ids["a"]} },
+			want: []string{"Work:c", "Work:a", "Work:b", "Home/Infra:d"},
+		},
+		{
+			name: "after bookmark in another folder",
+			move: func(ids map[string]string) BookmarkMove { return BookmarkMove{ID: ids["a"], After: ids["d"]} },
+			want: []string{"Work:b", "Work:c", "Home/Infra:d", "Home/Infra:a"},
+		},
+		{
+			name: "folder index",
+			move: func(ids map[string]string) BookmarkMove {
+				return BookmarkMove{ID: ids["d"], Folder: "Work", Index: index(1)}
+			},
+			want: []string{"Work:a", "Work:d", "Work:b", "Work:c"},
+		},
+		{
+			name: "new folder",
+			move: func(ids map[string]string) BookmarkMove { return BookmarkMove{ID: ids["b"], Folder: "Home/Infra/Nodes"} },
+			want: []string{"Work:a", "Work:c", "Home/Infra:d", "Home/Infra/Nodes:b"},
+		},
+		{
+			name: "category before",
+			move: func(map[string]string) BookmarkMove { return BookmarkMove{Path: "Home", Before: "Work"} },
+			want: []string{"Home/Infra:d", "Work:a", "Work:b", "Work:c"},
+		},
+		{
+			name:    "unknown sibling",
+			move:    func(ids map[string]string) BookmarkMove { return BookmarkMove{ID: ids["a"], Before: "missing"} },
+			wantErr: ErrBookmarkNotFound,
+		},
+		{
+			name:    "unknown folder",
+			move:    func(map[string]string) BookmarkMove { return BookmarkMove{Path: "Home/Media", Index: index(0)} },
+			wantErr: ErrFolderNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewBookmarkStore(t.TempDir())
+			ids := map[string]string{}
+			for _, seed := range []struct{ folder, name string }{{"Work", "a"}, {"Work", "b"}, {"Work", "c"}, {"Home/Infra", "d"}} {
+				link, err := store.Create(seed.folder, BookmarkLink{Name: seed.name, URL: "https://" + seed.name + ".example"})
+				if err != nil {
+					t.Fatalf("Create() error = %v", err)
+				}
+				ids[seed.name] = link.ID
+			}
+			err := store.Move(tt.move(ids))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Move() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			config, err := store.Load()
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if got := bookmarkNames(config); !slices.Equal(got, tt.want) {
+				t.Fatalf("order = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBookmarksReorderValidation(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	srv := New("", 0, store, NewHealthChecker(store, time.Hour), t.TempDir())
+	if err := srv.Setup(); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	for _, body := range []string{
+		`{"before":"x"}`,
+		`{"id":"a","path":"Work","before":"x"}`,
+		`{"id":"a","before":"x","after":"y"}`,
+		`{"id":"a","index":1}`,
+		`{"path":"Work","folder":"Home"}`,
+		`{"id":"a","folder":"Work","index":-1}`,
+	} {
+		rec := httptest.NewRecorder()
+		srv.httpServer.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/bookmarks/reorder", strings.NewReader(body)))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("POST reorder %s status = %d, want 400", body, rec.Code)
+		}
+	}
+}
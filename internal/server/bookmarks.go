@@ -1,17 +1,18 @@
 package server
 
 import (
+	"cmp"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"path/filepath"
 	"slices"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/goccy/go-yaml"
 	"github.com/google/uuid"
@@ -21,27 +22,38 @@ type BookmarkConfig struct {
 	Bookmarks []BookmarkCategory `yaml:"bookmarks" json:"bookmarks"`
 }
 
+// BookmarkCategory is a top-level folder. Categories and folders are pruned
+// once they hold no bookmarks unless they are pinned.
 type BookmarkCategory struct {
 	Category string           `yaml:"category" json:"category"`
 	Color    string           `yaml:"color,omitempty" json:"color,omitempty"`
+	Icon     string           `yaml:"icon,omitempty" json:"icon,omitempty"`
 	Folded   bool             `yaml:"folded,omitempty" json:"folded,omitempty"`
+	Pinned   bool             `yaml:"pinned,omitempty" json:"pinned,omitempty"`
 	Links    []BookmarkLink   `yaml:"links,omitempty" json:"links,omitempty"`
 	Folders  []BookmarkFolder `yaml:"folders,omitempty" json:"folders,omitempty"`
 }
 
+// BookmarkFolder may nest further folders to any depth. Files written before
+// nesting was supported simply have no sub-folders.
 type BookmarkFolder struct {
-	Name   string         `yaml:"name" json:"name"`
-	Icon   string         `yaml:"icon,omitempty" json:"icon,omitempty"`
-	Folded bool           `yaml:"folded,omitempty" json:"folded,omitempty"`
-	Links  []BookmarkLink `yaml:"links,omitempty" json:"links,omitempty"`
+	Name    string           `yaml:"name" json:"name"`
+	Color   string           `yaml:"color,omitempty" json:"color,omitempty"`
+	Icon    string           `yaml:"icon,omitempty" json:"icon,omitempty"`
+	Folded  bool             `yaml:"folded,omitempty" json:"folded,omitempty"`
+	Pinned  bool             `yaml:"pinned,omitempty" json:"pinned,omitempty"`
+	Links   []BookmarkLink   `yaml:"links,omitempty" json:"links,omitempty"`
+	Folders []BookmarkFolder `yaml:"folders,omitempty" json:"folders,omitempty"`
 }
 
 type BookmarkLink struct {
-	ID    string `yaml:"id,omitempty" json:"id,omitempty"`
-	Name  string `yaml:"name" json:"name"`
-	URL   string `yaml:"url" json:"url"`
-	Icon  string `yaml:"icon,omitempty" json:"icon,omitempty"`
-	Color string `yaml:"color,omitempty" json:"color,omitempty"`
+	ID        string    `yaml:"id,omitempty" json:"id,omitempty"`
+	Name      string    `yaml:"name" json:"name"`
+	URL       string    `yaml:"url" json:"url"`
+	Icon      string    `yaml:"icon,omitempty" json:"icon,omitempty"`
+	Color     string    `yaml:"color,omitempty" json:"color,omitempty"`
+	CreatedAt time.Time `yaml:"createdAt,omitempty" json:"createdAt,omitzero"`
+	UpdatedAt time.Time `yaml:"updatedAt,omitempty" json:"updatedAt,omitzero"`
 }
 
 type BookmarkInput struct {
@@ -52,26 +64,29 @@ type BookmarkInput struct {
 	Folder string `json:"folder"`
 }
 
-var ErrBookmarkNotFound = errors.New("bookmark not found")
-
 type BookmarkStore struct {
-	file string
-	mu   sync.Mutex
+	file     string
+	syncFile string
+	mu       sync.Mutex
+	events   *EventBus
 }
 
 func NewBookmarkStore(dataDir string) *BookmarkStore {
-	return &BookmarkStore{file: filepath.Join(dataDir, "bookmarks.yaml")}
+	return &BookmarkStore{
+		file:     filepath.Join(dataDir, "bookmarks.yaml"),
+		syncFile: filepath.Join(dataDir, "bookmarks-sync.json"),
+	}
 }
 
 func (s *BookmarkStore) Load() (BookmarkConfig, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	config, err := s.load()
+	config, migrated, err := s.loadNormalized()
 	if err != nil {
 		return BookmarkConfig{}, err
 	}
-	if ensureBookmarkIDs(&config) {
+	if migrated {
 		if err := s.save(config); err != nil {
 			return BookmarkConfig{}, err
 		}
@@ -85,7 +100,11 @@ func (s *BookmarkStore) Save(config BookmarkConfig) error {
 
 	ensureBookmarkIDs(&config)
 	pruneBookmarks(&config)
-	return s.save(config)
+	if err := s.save(config); err != nil {
+		return err
+	}
+	s.events.Publish(EventBookmarksReplaced, nil)
+	return nil
 }
 
 func (s *BookmarkStore) Create(folderPath string, link BookmarkLink) (BookmarkLink, error) {
@@ -96,20 +115,22 @@ func (s *BookmarkStore) Create(folderPath string, link BookmarkLink) (BookmarkLi
 	if err != nil {
 		return BookmarkLink{}, err
 	}
-	config, err := s.load()
+	config, _, err := s.loadNormalized()
 	if err != nil {
 		return BookmarkLink{}, err
 	}
-	ensureBookmarkIDs(&config)
 	if link.ID == "" {
 		link.ID = uuid.NewString()
 	}
 	link.Color = normalizeBookmarkColor(link.Color)
+	link.CreatedAt = time.Now().UTC()
+	link.UpdatedAt = link.CreatedAt
 	addBookmark(&config, path, link, BookmarkCategory{})
 	pruneBookmarks(&config)
 	if err := s.save(config); err != nil {
 		return BookmarkLink{}, err
 	}
+	s.events.Publish(EventBookmarkCreated, BookmarkEvent{Bookmark: link, Folder: strings.Join(path, "/")})
 	return link, nil
 }
 
@@ -121,21 +142,25 @@ func (s *BookmarkStore) Upsert(folderPath string, link BookmarkLink) (BookmarkLi
 	if err != nil {
 		return BookmarkLink{}, err
 	}
-	config, err := s.load()
+	config, _, err := s.loadNormalized()
 	if err != nil {
 		return BookmarkLink{}, err
 	}
-	ensureBookmarkIDs(&config)
 	if link.ID == "" {
 		link.ID = uuid.NewString()
 	}
 	link.Color = normalizeBookmarkColor(link.Color)
-	removeBookmarkMatch(&config, link.ID, link.URL)
+	link.UpdatedAt = time.Now().UTC()
+	link.CreatedAt = link.UpdatedAt
+	if existing, ok := removeBookmarkMatch(&config, link.ID, link.URL); ok {
+		link.CreatedAt = existing.CreatedAt
+	}
 	addBookmark(&config, path, link, BookmarkCategory{})
 	pruneBookmarks(&config)
 	if err := s.save(config); err != nil {
 		return BookmarkLink{}, err
 	}
+	s.events.Publish(EventBookmarkUpdated, BookmarkEvent{Bookmark: link, Folder: strings.Join(path, "/")})
 	return link, nil
 }
 
@@ -147,21 +172,30 @@ func (s *BookmarkStore) Update(id, folderPath string, link BookmarkLink) (Bookma
 	if err != nil {
 		return BookmarkLink{}, err
 	}
-	config, err := s.load()
+	config, _, err := s.loadNormalized()
 	if err != nil {
 		return BookmarkLink{}, err
 	}
-	ensureBookmarkIDs(&config)
-	if !removeBookmarkID(&config, id) {
+	links, index, folder := findBookmark(&config, id)
+	if links == nil {
 		return BookmarkLink{}, ErrBookmarkNotFound
 	}
 	link.ID = id
 	link.Color = normalizeBookmarkColor(link.Color)
-	addBookmark(&config, path, link, BookmarkCategory{})
+	link.CreatedAt = (*links)[index].CreatedAt
+	link.UpdatedAt = time.Now().UTC()
+	if slices.Equal(folder, path) {
+		// Edits in place keep the bookmark's position in its folder.
+		(*links)[index] = link
+	} else {
+		removeBookmarkID(&config, id)
+		addBookmark(&config, path, link, BookmarkCategory{})
+	}
 	pruneBookmarks(&config)
 	if err := s.save(config); err != nil {
 		return BookmarkLink{}, err
 	}
+	s.events.Publish(EventBookmarkUpdated, BookmarkEvent{Bookmark: link, Folder: strings.Join(path, "/")})
 	return link, nil
 }
 
@@ -169,16 +203,19 @@ func (s *BookmarkStore) Delete(id string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	config, err := s.load()
+	config, _, err := s.loadNormalized()
 	if err != nil {
 		return err
 	}
-	ensureBookmarkIDs(&config)
-	if !removeBookmarkID(&config, id) {
+	if _, ok := removeBookmarkID(&config, id); !ok {
 		return ErrBookmarkNotFound
 	}
 	pruneBookmarks(&config)
-	return s.save(config)
+	if err := s.save(config); err != nil {
+		return err
+	}
+	s.events.Publish(EventBookmarkDeleted, BookmarkEvent{Bookmark: BookmarkLink{ID: id}})
+	return nil
 }
 
 func (s *BookmarkStore) ExportJSON() ([]byte, error) {
@@ -210,17 +247,21 @@ func (s *BookmarkStore) ImportJSON(data []byte, mode string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if mode == "replace" {
-		return s.save(imported)
+	config := imported
+	if mode == "merge" {
+		existing, _, err := s.loadNormalized()
+		if err != nil {
+			return err
+		}
+		mergeBookmarks(&existing, imported)
+		pruneBookmarks(&existing)
+		config = existing
 	}
-	config, err := s.load()
-	if err != nil {
+	if err := s.save(config); err != nil {
 		return err
 	}
-	ensureBookmarkIDs(&config)
-	mergeBookmarks(&config, imported)
-	pruneBookmarks(&config)
-	return s.save(config)
+	s.events.Publish(EventBookmarksImported, ImportEvent{Mode: mode, Count: len(bookmarkLinks(imported))})
+	return nil
 }
 
 func (s *BookmarkStore) ReadRaw() ([]byte, error) {
@@ -242,7 +283,11 @@ func (s *BookmarkStore) WriteRaw(data []byte) error {
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	return s.save(config)
+	if err := s.save(config); err != nil {
+		return err
+	}
+	s.events.Publish(EventBookmarksReplaced, nil)
+	return nil
 }
 
 func (s *BookmarkStore) load() (BookmarkConfig, error) {
@@ -263,8 +308,33 @@ func (s *BookmarkStore) load() (BookmarkConfig, error) {
 	return config, nil
 }
 
+// loadNormalized loads the config and migrates it in memory: missing IDs are
+// assigned and bookmarks saved before timestamps were tracked are stamped with
+// the file's modification time. The flag reports whether anything changed.
+func (s *BookmarkStore) loadNormalized() (BookmarkConfig, bool, error) {
+	config, err := s.load()
+	if err != nil {
+		return BookmarkConfig{}, false, err
+	}
+	migrated := ensureBookmarkIDs(&config)
+	if info, err := os.Stat(s.file); err == nil {
+		migrated = backfillBookmarkTimes(&config, info.ModTime()) || migrated
+	}
+	return config, migrated, nil
+}
+
 func (s *BookmarkStore) save(config BookmarkConfig) error {
+	return s.saveChanges(config, nil)
+}
+
+// saveChanges writes the config and records how it differs from the file it
+// replaces in the sync change log. deletedAt carries the times of deletes
+// pushed by sync clients.
+func (s *BookmarkStore) saveChanges(config BookmarkConfig, deletedAt map[string]time.Time) (err error) {
+	started := time.Now()
+	defer func() { metrics.observeStoreWrite("bookmarks", time.Since(started), err) }()
 	ensureBookmarkIDs(&config)
+	backfillBookmarkTimes(&config, started)
 	pruneBookmarks(&config)
 	if config.Bookmarks == nil {
 		config.Bookmarks = []BookmarkCategory{}
@@ -273,9 +343,44 @@ func (s *BookmarkStore) save(config BookmarkConfig) error {
 	if err != nil {
 		return err
 	}
+	previous, err := s.load()
+	if err != nil {
+		// An unreadable file is replaced wholesale, so every bookmark is new.
+		previous = BookmarkConfig{}
+	}
+	// The log is written first: if the config write then fails, the next
+	// save diffs against the old file and logs the changes again, whereas
+	// the other way round clients would never hear of the write.
+	if err := s.recordChanges(diffBookmarks(previous, config, deletedAt, started.UTC())); err != nil {
+		return err
+	}
 	return os.WriteFile(s.file, data, 0644)
 }
 
+// forEachBookmark calls fn for every bookmark in the tree with the folder path
+// it is filed under, category first. The path slice is reused between calls.
+func forEachBookmark(config *BookmarkConfig, fn func(path []string, link *BookmarkLink)) {
+	for categoryIndex := range config.Bookmarks {
+		category := &config.Bookmarks[categoryIndex]
+		path := []string{category.Category}
+		for linkIndex := range category.Links {
+			fn(path, &category.Links[linkIndex])
+		}
+		forEachFolderBookmark(category.Folders, path, fn)
+	}
+}
+
+func forEachFolderBookmark(folders []BookmarkFolder, parent []string, fn func(path []string, link *BookmarkLink)) {
+	for folderIndex := range folders {
+		folder := &folders[folderIndex]
+		path := append(parent, folder.Name)
+		for linkIndex := range folder.Links {
+			fn(path, &folder.Links[linkIndex])
+		}
+		forEachFolderBookmark(folder.Folders, path, fn)
+	}
+}
+
 func ensureBookmarkIDs(config *BookmarkConfig) bool {
 	changed := false
 	seen := make(map[string]bool)
@@ -286,22 +391,39 @@ func ensureBookmarkIDs(config *BookmarkConfig) bool {
 			category.Color = color
 			changed = true
 		}
-		for linkIndex := range category.Links {
-			if normalizeBookmarkLink(&category.Links[linkIndex], seen) {
-				changed = true
-			}
+	}
+	forEachBookmark(config, func(_ []string, link *BookmarkLink) {
+		if normalizeBookmarkLink(link, seen) {
+			changed = true
+		}
+	})
+	return changed
+}
+
+func backfillBookmarkTimes(config *BookmarkConfig, stamp time.Time) bool {
+	changed := false
+	stamp = stamp.UTC()
+	forEachBookmark(config, func(_ []string, link *BookmarkLink) {
+		if link.CreatedAt.IsZero() {
+			link.CreatedAt = stamp
+			changed = true
 		}
-		for folderIndex := range category.Folders {
-			for linkIndex := range category.Folders[folderIndex].Links {
-				if normalizeBookmarkLink(&category.Folders[folderIndex].Links[linkIndex], seen) {
-					changed = true
-				}
-			}
+		if link.UpdatedAt.IsZero() {
+			link.UpdatedAt = link.CreatedAt
+			changed = true
 		}
-	}
+	})
 	return changed
 }
 
+func bookmarkLinks(config BookmarkConfig) []BookmarkLink {
+	var links []BookmarkLink
+	forEachBookmark(&config, func(_ []string, link *BookmarkLink) {
+		links = append(links, *link)
+	})
+	return links
+}
+
 func normalizeBookmarkLink(link *BookmarkLink, seen map[string]bool) bool {
 	changed := false
 	if link.ID == "" || seen[link.ID] {
@@ -327,9 +449,6 @@ func parseBookmarkPath(folderPath string) ([]string, error) {
 		return []string{"Uncategorized"}, nil
 	}
 	parts := strings.Split(folderPath, "/")
-	if len(parts) > 2 {
-		return nil, fmt.Errorf("bookmark folder path supports at most category/folder")
-	}
 	for i := range parts {
 		parts[i] = strings.TrimSpace(parts[i])
 		if parts[i] == "" {
@@ -340,239 +459,203 @@ func parseBookmarkPath(folderPath string) ([]string, error) {
 }
 
 func addBookmark(config *BookmarkConfig, path []string, link BookmarkLink, source BookmarkCategory) {
+	// A new category takes its color from the first bookmark filed in it.
+	source.Color = cmp.Or(source.Color, link.Color)
+	links := bookmarkLinksAt(config, path, source)
+	*links = append(*links, link)
+}
+
+// bookmarkLinksAt returns the bookmark list of the folder at path, creating
+// the category and folders on the way. New ones copy their settings from the
+// matching entries of source.
+func bookmarkLinksAt(config *BookmarkConfig, path []string, source BookmarkCategory) *[]BookmarkLink {
 	categoryIndex := slices.IndexFunc(config.Bookmarks, func(category BookmarkCategory) bool {
 		return category.Category == path[0]
 	})
 	if categoryIndex == -1 {
-		color := source.Color
-		if color == "" {
-			color = link.Color
-		}
 		config.Bookmarks = append(config.Bookmarks, BookmarkCategory{
 			Category: path[0],
-			Color:    normalizeBookmarkColor(color),
+			Color:    normalizeBookmarkColor(source.Color),
 			Folded:   source.Folded,
 		})
 		categoryIndex = len(config.Bookmarks) - 1
 	}
 	category := &config.Bookmarks[categoryIndex]
-	if len(path) == 1 {
-		category.Links = append(category.Links, link)
-		return
-	}
-	folderIndex := slices.IndexFunc(category.Folders, func(folder BookmarkFolder) bool {
-		return folder.Name == path[1]
-	})
-	if folderIndex == -1 {
-		var folder BookmarkFolder
-		for _, candidate := range source.Folders {
-			if candidate.Name == path[1] {
-				folder = candidate
-				folder.Links = nil
-				break
-			}
+	links, folders, sourceFolders := &category.Links, &category.Folders, source.Folders
+	for _, name := range path[1:] {
+		named := func(folder BookmarkFolder) bool { return folder.Name == name }
+		var template BookmarkFolder
+		if sourceIndex := slices.IndexFunc(sourceFolders, named); sourceIndex != -1 {
+			template = sourceFolders[sourceIndex]
+			sourceFolders = template.Folders
+		} else {
+			sourceFolders = nil
+		}
+		folderIndex := slices.IndexFunc(*folders, named)
+		if folderIndex == -1 {
+			template.Name, template.Links, template.Folders = name, nil, nil
+			*folders = append(*folders, template)
+			folderIndex = len(*folders) - 1
 		}
-		folder.Name = path[1]
-		category.Folders = append(category.Folders, folder)
-		folderIndex = len(category.Folders) - 1
+		folder := &(*folders)[folderIndex]
+		links, folders = &folder.Links, &folder.Folders
 	}
-	category.Folders[folderIndex].Links = append(category.Folders[folderIndex].Links, link)
+	return links
 }
 
-func removeBookmarkID(config *BookmarkConfig, id string) bool {
-	removed := false
-	for categoryIndex := range config.Bookmarks {
-		category := &config.Bookmarks[categoryIndex]
-		before := len(category.Links)
-		category.Links = slices.DeleteFunc(category.Links, func(link BookmarkLink) bool {
-			return link.ID == id
-		})
-		removed = removed || len(category.Links) != before
-		for folderIndex := range category.Folders {
-			folder := &category.Folders[folderIndex]
-			before = len(folder.Links)
-			folder.Links = slices.DeleteFunc(folder.Links, func(link BookmarkLink) bool {
-				return link.ID == id
-			})
-			removed = removed || len(folder.Links) != before
-		}
-	}
-	return removed
+// removeBookmarkID removes the bookmark with the given ID and returns it.
+func removeBookmarkID(config *BookmarkConfig, id string) (BookmarkLink, bool) {
+	return removeBookmarkMatch(config, id, "")
 }
 
-func removeBookmarkMatch(config *BookmarkConfig, id, bookmarkURL string) string {
-	existingID := ""
+// removeBookmarkMatch removes every bookmark with the given ID or URL and
+// returns the first one removed.
+func removeBookmarkMatch(config *BookmarkConfig, id, bookmarkURL string) (BookmarkLink, bool) {
+	var existing BookmarkLink
+	found := false
+	matches := func(link BookmarkLink) bool {
+		match := (id != "" && link.ID == id) || (bookmarkURL != "" && link.URL == bookmarkURL)
+		if match && !found {
+			existing, found = link, true
+		}
+		return match
+	}
+	var removeFrom func(folders []BookmarkFolder)
+	removeFrom = func(folders []BookmarkFolder) {
+		for folderIndex := range folders {
+			folder := &folders[folderIndex]
+			folder.Links = slices.DeleteFunc(folder.Links, matches)
+			removeFrom(folder.Folders)
+		}
+	}
 	for categoryIndex := range config.Bookmarks {
 		category := &config.Bookmarks[categoryIndex]
-		category.Links = slices.DeleteFunc(category.Links, func(link BookmarkLink) bool {
-			match := (id != "" && link.ID == id) || (bookmarkURL != "" && link.URL == bookmarkURL)
-			if match && existingID == "" {
-				existingID = link.ID
-			}
-			return match
-		})
-		for folderIndex := range category.Folders {
-			folder := &category.Folders[folderIndex]
-			folder.Links = slices.DeleteFunc(folder.Links, func(link BookmarkLink) bool {
-				match := (id != "" && link.ID == id) || (bookmarkURL != "" && link.URL == bookmarkURL)
-				if match && existingID == "" {
-					existingID = link.ID
-				}
-				return match
-			})
-		}
+		category.Links = slices.DeleteFunc(category.Links, matches)
+		removeFrom(category.Folders)
 	}
-	return existingID
+	return existing, found
 }
 
 func pruneBookmarks(config *BookmarkConfig) {
 	for categoryIndex := range config.Bookmarks {
 		category := &config.Bookmarks[categoryIndex]
-		category.Folders = slices.DeleteFunc(category.Folders, func(folder BookmarkFolder) bool {
-			return len(folder.Links) == 0
-		})
+		category.Folders = pruneBookmarkFolders(category.Folders)
 	}
 	config.Bookmarks = slices.DeleteFunc(config.Bookmarks, func(category BookmarkCategory) bool {
-		return len(category.Links) == 0 && len(category.Folders) == 0
+		return !category.Pinned && len(category.Links) == 0 && len(category.Folders) == 0
+	})
+}
+
+// pruneBookmarkFolders drops folders left without bookmarks, innermost first.
+func pruneBookmarkFolders(folders []BookmarkFolder) []BookmarkFolder {
+	for folderIndex := range folders {
+		folders[folderIndex].Folders = pruneBookmarkFolders(folders[folderIndex].Folders)
+	}
+	return slices.DeleteFunc(folders, func(folder BookmarkFolder) bool {
+		return !folder.Pinned && len(folder.Links) == 0 && len(folder.Folders) == 0
 	})
 }
 
 func mergeBookmarks(config *BookmarkConfig, imported BookmarkConfig) {
-	for _, category := range imported.Bookmarks {
-		for _, link := range category.Links {
-			if existingID := removeBookmarkMatch(config, link.ID, link.URL); existingID != "" {
-				link.ID = existingID
-			}
-			addBookmark(config, []string{category.Category}, link, category)
-		}
-		for _, folder := range category.Folders {
-			for _, link := range folder.Links {
-				if existingID := removeBookmarkMatch(config, link.ID, link.URL); existingID != "" {
-					link.ID = existingID
-				}
-				addBookmark(config, []string{category.Category, folder.Name}, link, category)
-			}
+	now := time.Now().UTC()
+	merge := func(path []string, link BookmarkLink, source BookmarkCategory) {
+		if existing, ok := removeBookmarkMatch(config, link.ID, link.URL); ok {
+			link.ID = existing.ID
+			link.CreatedAt = existing.CreatedAt
+			link.UpdatedAt = now
 		}
+		addBookmark(config, path, link, source)
+	}
+	for _, category := range imported.Bookmarks {
+		single := BookmarkConfig{Bookmarks: []BookmarkCategory{category}}
+		forEachBookmark(&single, func(path []string, link *BookmarkLink) {
+			merge(path, *link, category)
+		})
 	}
 }
 
-func (s *Server) handleBookmarks(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		config, err := s.bookmarks.Load()
-		if err != nil {
-			log.Printf("ERROR Failed to load bookmarks: %v", err)
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-			return
-		}
-		writeJSON(w, http.StatusOK, config.Bookmarks)
-	case http.MethodPost:
-		input, ok := decodeBookmarkInput(w, r)
-		if !ok {
-			return
-		}
-		link, err := s.bookmarks.Create(input.Folder, input.BookmarkLink())
-		if err != nil {
-			log.Printf("ERROR Failed to create bookmark: %v", err)
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-			return
-		}
-		writeJSON(w, http.StatusCreated, link)
-	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+func (s *Server) handleListBookmarks(w http.ResponseWriter, r *http.Request) {
+	config, err := s.bookmarks.Load()
+	if err != nil {
+		writeError(w, r, fmt.Errorf("load bookmarks: %w", err))
+		return
 	}
+	writeJSON(w, http.StatusOK, config.Bookmarks)
 }
 
-func (s *Server) handleBookmarksPath(w http.ResponseWriter, r *http.Request) {
-	path := strings.TrimPrefix(r.URL.Path, "/api/bookmarks/")
-	switch path {
-	case "export":
-		if r.Method != http.MethodGet {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			return
-		}
-		data, err := s.bookmarks.ExportJSON()
-		if err != nil {
-			log.Printf("ERROR Failed to export bookmarks: %v", err)
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-			return
-		}
-		w.Header().Set("Content-Type", "application/json")
-		w.Write(data)
-	case "import":
-		if r.Method != http.MethodPost {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			return
-		}
-		data, mode, err := decodeBookmarkImport(r)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
-		}
-		if err := s.bookmarks.ImportJSON(data, mode); err != nil {
-			log.Printf("ERROR Failed to import bookmarks: %v", err)
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-			return
-		}
-		w.WriteHeader(http.StatusNoContent)
-	default:
-		if path == "" || strings.Contains(path, "/") {
-			http.NotFound(w, r)
-			return
-		}
-		s.handleBookmarkByID(w, r, path)
+func (s *Server) handleCreateBookmark(w http.ResponseWriter, r *http.Request) {
+	input, ok := decodeBookmarkInput(w, r)
+	if !ok {
+		return
+	}
+	link, err := s.bookmarks.Create(input.Folder, input.BookmarkLink())
+	if err != nil {
+		writeError(w, r, fmt.Errorf("create bookmark: %w", err))
+		return
 	}
+	writeJSON(w, http.StatusCreated, link)
 }
 
-func (s *Server) handleBookmarkByID(w http.ResponseWriter, r *http.Request, id string) {
-	switch r.Method {
-	case http.MethodPut:
-		input, ok := decodeBookmarkInput(w, r)
-		if !ok {
-			return
-		}
-		link, err := s.bookmarks.Update(id, input.Folder, input.BookmarkLink())
-		if errors.Is(err, ErrBookmarkNotFound) {
-			http.Error(w, err.Error(), http.StatusNotFound)
-			return
-		}
-		if err != nil {
-			log.Printf("ERROR Failed to update bookmark %s: %v", id, err)
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-			return
-		}
-		writeJSON(w, http.StatusOK, link)
-	case http.MethodDelete:
-		err := s.bookmarks.Delete(id)
-		if errors.Is(err, ErrBookmarkNotFound) {
-			http.Error(w, err.Error(), http.StatusNotFound)
-			return
-		}
-		if err != nil {
-			log.Printf("ERROR Failed to delete bookmark %s: %v", id, err)
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-			return
-		}
-		w.WriteHeader(http.StatusNoContent)
-	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+func (s *Server) handleBookmarksExport(w http.ResponseWriter, r *http.Request) {
+	data, err := s.bookmarks.ExportJSON()
+	if err != nil {
+		writeError(w, r, fmt.Errorf("export bookmarks: %w", err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func (s *Server) handleBookmarksImport(w http.ResponseWriter, r *http.Request) {
+	data, mode, err := decodeBookmarkImport(r)
+	if err != nil {
+		writeError(w, r, invalidRequest(err.Error()))
+		return
 	}
+	if err := s.bookmarks.ImportJSON(data, mode); err != nil {
+		writeError(w, r, fmt.Errorf("import bookmarks: %w", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleUpdateBookmark(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	input, ok := decodeBookmarkInput(w, r)
+	if !ok {
+		return
+	}
+	link, err := s.bookmarks.Update(id, input.Folder, input.BookmarkLink())
+	if err != nil {
+		writeError(w, r, fmt.Errorf("update bookmark %s: %w", id, err))
+		return
+	}
+	writeJSON(w, http.StatusOK, link)
+}
+
+func (s *Server) handleDeleteBookmark(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := s.bookmarks.Delete(id); err != nil {
+		writeError(w, r, fmt.Errorf("delete bookmark %s: %w", id, err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
 }
 
 func decodeBookmarkInput(w http.ResponseWriter, r *http.Request) (BookmarkInput, bool) {
 	var input BookmarkInput
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+		writeError(w, r, ErrInvalidJSON)
 		return BookmarkInput{}, false
 	}
 	input.Name = strings.TrimSpace(input.Name)
 	input.URL = strings.TrimSpace(input.URL)
 	if input.Name == "" || input.URL == "" {
-		http.Error(w, "name and url are required", http.StatusBadRequest)
+		writeError(w, r, invalidRequest("name and url are required"))
 		return BookmarkInput{}, false
 	}
 	if _, err := parseBookmarkPath(input.Folder); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, invalidRequest(err.Error()))
 		return BookmarkInput{}, false
 	}
 	return input, true
@@ -615,34 +698,26 @@ func decodeBookmarkImport(r *http.Request) ([]byte, string, error) {
 	return data, mode, nil
 }
 
-func (s *Server) handleConfigRaw(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		data, err := s.bookmarks.ReadRaw()
-		if err != nil {
-			log.Printf("ERROR Failed to read bookmarks config: %v", err)
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-			return
-		}
-		w.Header().Set("Content-Type", "text/plain")
-		w.Write(data)
-
-	case http.MethodPost:
-		data, err := io.ReadAll(r.Body)
-		if err != nil {
-			log.Printf("ERROR Failed to read request body: %v", err)
-			http.Error(w, "Bad Request", http.StatusBadRequest)
-			return
-		}
-
-		if err := s.bookmarks.WriteRaw(data); err != nil {
-			log.Printf("ERROR Invalid YAML provided: %v", err)
-			http.Error(w, "Invalid YAML format", http.StatusBadRequest)
-			return
-		}
-		w.WriteHeader(http.StatusOK)
+func (s *Server) handleReadConfig(w http.ResponseWriter, r *http.Request) {
+	data, err := s.bookmarks.ReadRaw()
+	if err != nil {
+		writeError(w, r, fmt.Errorf("read bookmarks config: %w", err))
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write(data)
+}
 
-	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+func (s *Server) handleWriteConfig(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, r, invalidRequest("failed to read request body"))
+		return
+	}
+	if err := s.bookmarks.WriteRaw(data); err != nil {
+		slog.WarnContext(r.Context(), "invalid bookmarks YAML", "error", err)
+		writeError(w, r, ErrInvalidYAML)
+		return
 	}
+	w.WriteHeader(http.StatusOK)
 }
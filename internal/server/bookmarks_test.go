@@ -4,21 +4,24 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 )
 
 func TestBookmarkPathPlacement(t *testing.T) {
 	tests := []struct {
-		name       string
-		path       string
-		wantFolder string
-		wantErr    bool
+		name        string
+		path        string
+		wantFolders []string
+		wantErr     bool
 	}{
 		{name: "category", path: "Work"},
-		{name: "nested folder", path: "Homelab/Infra", wantFolder: "Infra"},
+		{name: "nested folder", path: "Homelab/Infra", wantFolders: []string{"Infra"}},
+		{name: "deep path", path: "Homelab/Infra/Proxmox/Nodes", wantFolders: []string{"Infra", "Proxmox", "Nodes"}},
 		{name: "empty defaults", path: ""},
-		{name: "deep path rejected", path: "A/B/C", wantErr: true},
 		{name: "empty segment rejected", path: "A//B", wantErr: true},
 	}
 
@@ -43,22 +46,73 @@ func TestBookmarkPathPlacement(t *testing.T) {
 			if tt.path == "" && category.Category != "Uncategorized" {
 				t.Fatalf("category = %q, want Uncategorized", category.Category)
 			}
-			if tt.wantFolder == "" {
-				if len(category.Links) != 1 || category.Links[0].ID != created.ID {
-					t.Fatalf("category links = %#v, want created link", category.Links)
+			links, folders := category.Links, category.Folders
+			for _, name := range tt.wantFolders {
+				if len(links) != 0 || len(folders) != 1 || folders[0].Name != name {
+					t.Fatalf("links = %#v, folders = %#v, want only folder %q", links, folders, name)
 				}
-				return
-			}
-			if len(category.Folders) != 1 || category.Folders[0].Name != tt.wantFolder {
-				t.Fatalf("folders = %#v, want %q", category.Folders, tt.wantFolder)
+				links, folders = folders[0].Links, folders[0].Folders
 			}
-			if len(category.Folders[0].Links) != 1 || category.Folders[0].Links[0].ID != created.ID {
-				t.Fatalf("folder links = %#v, want created link", category.Folders[0].Links)
+			if len(folders) != 0 || len(links) != 1 || links[0].ID != created.ID {
+				t.Fatalf("innermost links = %#v, folders = %#v, want created link", links, folders)
 			}
 		})
 	}
 }
 
+func TestBookmarkNestedFolders(t *testing.T) {
+	dataDir := t.TempDir()
+	legacy := []byte("bookmarks:\n  - category: Homelab\n    folders:\n      - name: Infra\n        icon: server\n        links:\n          - name: Router\n            url: https://router.lan\n")
+	if err := os.WriteFile(filepath.Join(dataDir, "bookmarks.yaml"), legacy, 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	store := NewBookmarkStore(dataDir)
+	node, err := store.Create("Homelab/Infra/Proxmox/Nodes", BookmarkLink{Name: "pve1", URL: "https://pve1.lan"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	config, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	infra := config.Bookmarks[0].Folders[0]
+	if infra.Icon != "server" || len(infra.Links) != 1 || len(infra.Folders) != 1 {
+		t.Fatalf("Infra folder = %#v, want legacy link, icon and new sub-folder", infra)
+	}
+	var paths []string
+	forEachBookmark(&config, func(path []string, link *BookmarkLink) {
+		paths = append(paths, strings.Join(path, "/")+":"+link.Name)
+	})
+	if want := []string{"Homelab/Infra:Router", "Homelab/Infra/Proxmox/Nodes:pve1"}; !slices.Equal(paths, want) {
+		t.Fatalf("bookmark paths = %v, want %v", paths, want)
+	}
+
+	// Moving the only bookmark out of a deep branch prunes every empty folder.
+	if _, err := store.Update(node.ID, "Homelab", node); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	config, err = store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if folders := config.Bookmarks[0].Folders; len(folders) != 1 || len(folders[0].Folders) != 0 {
+		t.Fatalf("folders after move = %#v, want only Infra", folders)
+	}
+
+	imported := `{"bookmarks":[{"category":"Homelab","folders":[{"name":"Infra","folders":[{"name":"Proxmox","icon":"cpu","links":[{"name":"Router","url":"https://router.lan"}]}]}]}]}`
+	if err := store.ImportJSON([]byte(imported), "merge"); err != nil {
+		t.Fatalf("ImportJSON() error = %v", err)
+	}
+	config, err = store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	infra = config.Bookmarks[0].Folders[0]
+	if len(infra.Links) != 0 || len(infra.Folders) != 1 || infra.Folders[0].Icon != "cpu" || infra.Folders[0].Links[0].URL != "https://router.lan" {
+		t.Fatalf("Infra after merge = %#v, want Router moved into Proxmox", infra)
+	}
+}
+
 func TestBookmarkPruneAndMove(t *testing.T) {
 	store := NewBookmarkStore(t.TempDir())
 	created, err := store.Create("Old/Folder", BookmarkLink{Name: "Link", URL: "https://example.com"})
@@ -240,7 +294,7 @@ func TestLinkImportModesAndHealth(t *testing.T) {
 				t.Fatalf("GetLinks() count = %d, want %d", len(links), tt.wantCount)
 			}
 			same := links[0]
-			if tt.mode == "merge" && (same.ID != existing.ID || same.Health != existing.Health || !same.LastChecked.Equal(checked)) {
+			if tt.mode == "merge" && (same.ID != existing.ID || !reflect.DeepEqual(same.Health, existing.Health) || !same.LastChecked.Equal(checked)) {
 				t.Fatalf("merged link = %#v, want preserved ID and health", same)
 			}
 			for _, link := range links {
@@ -272,18 +326,7 @@ func TestUpdateLinkHealthPreservation(t *testing.T) {
 		t.Fatalf("UpdateLink() error = %v", err)
 	}
 	got := store.GetLinks()[0]
-	if got.Health != existing.Health || !got.LastChecked.Equal(checked) {
+	if !reflect.DeepEqual(got.Health, existing.Health) || !got.LastChecked.Equal(checked) {
 		t.Fatalf("UpdateLink() health = %#v/%v, want preserved", got.Health, got.LastChecked)
 	}
 }
-
-func bookmarkLinks(config BookmarkConfig) []BookmarkLink {
-	var links []BookmarkLink
-	for _, category := range config.Bookmarks {
-		links = append(links, category.Links...)
-		for _, folder := range category.Folders {
-			links = append(links, folder.Links...)
-		}
-	}
-	return links
-}
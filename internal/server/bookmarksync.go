@@ -0,0 +1,393 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxBookmarkChanges bounds the change log. Clients that fall further behind
+// get a full snapshot instead of deltas.
+const maxBookmarkChanges = 5000
+
+const (
+	bookmarkChangeUpsert = "upsert"
+	bookmarkChangeDelete = "delete"
+)
+
+// BookmarkChange is one entry of the bookmark change log, and what sync
+// clients push. An upsert carries the bookmark, its folder and its position
+// there; a delete only the ID. UpdatedAt is the time of the write and decides
+// conflicts: the latest write per bookmark ID wins.
+type BookmarkChange struct {
+	Revision  int64         `json:"revision,omitempty"`
+	Op        string        `json:"op"`
+	ID        string        `json:"id"`
+	Bookmark  *BookmarkLink `json:"bookmark,omitempty"`
+	Folder    string        `json:"folder,omitempty"`
+	Index     *int          `json:"index,omitempty"`
+	UpdatedAt time.Time     `json:"updatedAt"`
+}
+
+func (c BookmarkChange) validate() error {
+	if c.ID == "" {
+		return errors.New("id is required")
+	}
+	if c.UpdatedAt.IsZero() {
+		return errors.New("updatedAt is required")
+	}
+	switch c.Op {
+	case bookmarkChangeDelete:
+		return nil
+	case bookmarkChangeUpsert:
+		if c.Bookmark == nil || strings.TrimSpace(c.Bookmark.Name) == "" || strings.TrimSpace(c.Bookmark.URL) == "" {
+			return errors.New("an upsert needs a bookmark with a name and url")
+		}
+		_, err := parseBookmarkPath(c.Folder)
+		return err
+	}
+	return errors.New("op must be upsert or delete")
+}
+
+// BookmarkSyncRequest pushes a client's changes and pulls everything after
+// the revision the client last saw.
+type BookmarkSyncRequest struct {
+	Since   int64            `json:"since"`
+	Changes []BookmarkChange `json:"changes"`
+}
+
+func (r BookmarkSyncRequest) validate() error {
+	if r.Since < 0 {
+		return invalidRequest("since must not be negative")
+	}
+	for i, change := range r.Changes {
+		if err := change.validate(); err != nil {
+			return invalidRequest(fmt.Sprintf("change %d: %v", i, err))
+		}
+	}
+	return nil
+}
+
+// BookmarkSyncResult reports a pushed change: "applied", or "stale" when the
+// server holds a later write for the bookmark.
+type BookmarkSyncResult struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+// BookmarkSync answers a pull. Changes are compacted to the latest one per
+// bookmark. With Reset set they are a snapshot of every bookmark and the
+// client should drop bookmarks it has that are not in it.
+type BookmarkSync struct {
+	Revision int64                `json:"revision"`
+	Reset    bool                 `json:"reset"`
+	Changes  []BookmarkChange     `json:"changes"`
+	Results  []BookmarkSyncResult `json:"results,omitempty"`
+}
+
+// bookmarkSyncLog is stored next to bookmarks.yaml. Changes at or below Floor
+// have been dropped from it.
+type bookmarkSyncLog struct {
+	Revision int64            `json:"revision"`
+	Floor    int64            `json:"floor"`
+	Changes  []BookmarkChange `json:"changes"`
+}
+
+// bookmarkEntry is a bookmark with where it is filed.
+type bookmarkEntry struct {
+	link   BookmarkLink
+	folder string
+	index  int
+}
+
+func (e bookmarkEntry) change(revision int64) BookmarkChange {
+	link := e.link
+	return BookmarkChange{
+		Revision:  revision,
+		Op:        bookmarkChangeUpsert,
+		ID:        link.ID,
+		Bookmark:  &link,
+		Folder:    e.folder,
+		Index:     &e.index,
+		UpdatedAt: link.UpdatedAt,
+	}
+}
+
+// same reports whether other is the same bookmark in the same folder. The
+// position is left out: a write to one bookmark shifts the ones after it,
+// and clients shift them too when they replay that write.
+func (e bookmarkEntry) same(other bookmarkEntry) bool {
+	a, b := e.link, other.link
+	return e.folder == other.folder &&
+		a.Name == b.Name && a.URL == b.URL && a.Icon == b.Icon && a.Color == b.Color &&
+		a.CreatedAt.Equal(b.CreatedAt) && a.UpdatedAt.Equal(b.UpdatedAt)
+}
+
+// bookmarkEntries lists the bookmarks of a config in tree order.
+func bookmarkEntries(config BookmarkConfig) []bookmarkEntry {
+	var entries []bookmarkEntry
+	positions := map[string]int{}
+	forEachBookmark(&config, func(path []string, link *BookmarkLink) {
+		folder := strings.Join(path, "/")
+		entries = append(entries, bookmarkEntry{link: *link, folder: folder, index: positions[folder]})
+		positions[folder]++
+	})
+	return entries
+}
+
+// diffBookmarks returns the changes that turn previous into current. Deletes
+// are stamped with deletedAt when a client supplied the time, otherwise now;
+// IDs in deletedAt that were already gone are still recorded as tombstones.
+// Folder renames and removals leave a bookmark's own time alone, so a
+// bookmark whose folder changed under it is stamped now as well, or an older
+// push would undo the change.
+func diffBookmarks(previous, current BookmarkConfig, deletedAt map[string]time.Time, now time.Time) []BookmarkChange {
+	before := map[string]bookmarkEntry{}
+	previousEntries := bookmarkEntries(previous)
+	for _, entry := range previousEntries {
+		before[entry.link.ID] = entry
+	}
+	var changes []BookmarkChange
+	kept := map[string]bool{}
+	for _, entry := range bookmarkEntries(current) {
+		kept[entry.link.ID] = true
+		old, ok := before[entry.link.ID]
+		if ok && old.same(entry) {
+			continue
+		}
+		change := entry.change(0)
+		if ok && old.folder != entry.folder && old.link.UpdatedAt.Equal(entry.link.UpdatedAt) {
+			change.UpdatedAt = now
+		}
+		changes = append(changes, change)
+	}
+	deleted := func(id string) BookmarkChange {
+		stamp, ok := deletedAt[id]
+		if !ok {
+			stamp = now
+		}
+		return BookmarkChange{Op: bookmarkChangeDelete, ID: id, UpdatedAt: stamp}
+	}
+	for _, entry := range previousEntries {
+		if id := entry.link.ID; id != "" && !kept[id] {
+			changes = append(changes, deleted(id))
+		}
+	}
+	for _, id := range slices.Sorted(maps.Keys(deletedAt)) {
+		if _, ok := before[id]; !ok && !kept[id] {
+			changes = append(changes, deleted(id))
+		}
+	}
+	return changes
+}
+
+func (s *BookmarkStore) loadSyncLog() (bookmarkSyncLog, error) {
+	data, err := os.ReadFile(s.syncFile)
+	if os.IsNotExist(err) {
+		return bookmarkSyncLog{}, nil
+	}
+	if err != nil {
+		return bookmarkSyncLog{}, err
+	}
+	var log bookmarkSyncLog
+	if err := json.Unmarshal(data, &log); err != nil {
+		return bookmarkSyncLog{}, fmt.Errorf("read bookmark sync log: %w", err)
+	}
+	return log, nil
+}
+
+// recordChanges appends the changes as the next revision, trimming the log
+// to maxBookmarkChanges.
+func (s *BookmarkStore) recordChanges(changes []BookmarkChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	log, err := s.loadSyncLog()
+	if err != nil {
+		return err
+	}
+	log.Revision++
+	for i := range changes {
+		changes[i].Revision = log.Revision
+	}
+	log.Changes = append(log.Changes, changes...)
+	if excess := len(log.Changes) - maxBookmarkChanges; excess > 0 {
+		log.Floor = log.Changes[excess-1].Revision
+		log.Changes = slices.Delete(log.Changes, 0, excess)
+	}
+	data, err := json.Marshal(log)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.syncFile, data, 0644)
+}
+
+// lastWrite is the time of the latest write the server knows for a bookmark,
+// zero when it has never seen the ID.
+func lastWrite(log bookmarkSyncLog, config *BookmarkConfig, id string) time.Time {
+	for _, change := range slices.Backward(log.Changes) {
+		if change.ID == id {
+			return change.UpdatedAt
+		}
+	}
+	if links, index, _ := findBookmark(config, id); links != nil {
+		return (*links)[index].UpdatedAt
+	}
+	return time.Time{}
+}
+
+// pull returns what a client at revision since is missing. Clients that have
+// never synced, were trimmed out of the log or come from another server get
+// a snapshot.
+func pull(log bookmarkSyncLog, config BookmarkConfig, since int64) BookmarkSync {
+	result := BookmarkSync{Revision: log.Revision, Changes: []BookmarkChange{}}
+	if since <= 0 || since < log.Floor || since > log.Revision {
+		result.Reset = true
+		for _, entry := range bookmarkEntries(config) {
+			result.Changes = append(result.Changes, entry.change(log.Revision))
+		}
+		return result
+	}
+	latest := map[string]int{}
+	for i, change := range log.Changes {
+		if change.Revision > since {
+			latest[change.ID] = i
+		}
+	}
+	for i, change := range log.Changes {
+		if index, ok := latest[change.ID]; ok && index == i {
+			result.Changes = append(result.Changes, change)
+		}
+	}
+	return result
+}
+
+// Changes pulls the changes after revision since without pushing any.
+func (s *BookmarkStore) Changes(since int64) (BookmarkSync, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	config, _, err := s.loadNormalized()
+	if err != nil {
+		return BookmarkSync{}, err
+	}
+	log, err := s.loadSyncLog()
+	if err != nil {
+		return BookmarkSync{}, err
+	}
+	return pull(log, config, since), nil
+}
+
+// Sync applies the pushed changes that are newer than the server's last
+// write for their bookmark, saves once, and pulls everything after
+// request.Since, including the changes just applied.
+func (s *BookmarkStore) Sync(request BookmarkSyncRequest) (BookmarkSync, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	config, _, err := s.loadNormalized()
+	if err != nil {
+		return BookmarkSync{}, err
+	}
+	log, err := s.loadSyncLog()
+	if err != nil {
+		return BookmarkSync{}, err
+	}
+	results := make([]BookmarkSyncResult, len(request.Changes))
+	written := map[string]time.Time{}
+	deletedAt := map[string]time.Time{}
+	applied := 0
+	for i, change := range request.Changes {
+		results[i] = BookmarkSyncResult{ID: change.ID, Status: "stale"}
+		last, ok := written[change.ID]
+		if !ok {
+			last = lastWrite(log, &config, change.ID)
+		}
+		if !change.UpdatedAt.After(last) {
+			continue
+		}
+		existing, found := removeBookmarkID(&config, change.ID)
+		if change.Op == bookmarkChangeUpsert {
+			link := *change.Bookmark
+			link.ID = change.ID
+			link.Name, link.URL = strings.TrimSpace(link.Name), strings.TrimSpace(link.URL)
+			link.Color = normalizeBookmarkColor(link.Color)
+			link.UpdatedAt = change.UpdatedAt.UTC()
+			link.CreatedAt = link.UpdatedAt
+			if found {
+				link.CreatedAt = existing.CreatedAt
+			}
+			path, _ := parseBookmarkPath(change.Folder)
+			links := bookmarkLinksAt(&config, path, BookmarkCategory{Color: link.Color})
+			index := len(*links)
+			if change.Index != nil {
+				index = min(max(*change.Index, 0), index)
+			}
+			*links = slices.Insert(*links, index, link)
+			delete(deletedAt, change.ID)
+		} else {
+			deletedAt[change.ID] = change.UpdatedAt.UTC()
+		}
+		written[change.ID] = change.UpdatedAt
+		results[i].Status = "applied"
+		applied++
+	}
+	if applied > 0 {
+		pruneBookmarks(&config)
+		if err := s.saveChanges(config, deletedAt); err != nil {
+			return BookmarkSync{}, err
+		}
+		if log, err = s.loadSyncLog(); err != nil {
+			return BookmarkSync{}, err
+		}
+	}
+	result := pull(log, config, request.Since)
+	result.Results = results
+	if applied > 0 {
+		s.events.Publish(EventBookmarksSynced, BookmarkSyncEvent{Revision: result.Revision, Applied: applied})
+	}
+	return result, nil
+}
+
+func (s *Server) handleBookmarkChanges(w http.ResponseWriter, r *http.Request) {
+	var since int64
+	if value := r.URL.Query().Get("since"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || parsed < 0 {
+			writeError(w, r, invalidRequest("since must be a revision number"))
+			return
+		}
+		since = parsed
+	}
+	result, err := s.bookmarks.Changes(since)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("pull bookmark changes: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleBookmarkSync(w http.ResponseWriter, r *http.Request) {
+	var request BookmarkSyncRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, r, ErrInvalidJSON)
+		return
+	}
+	if err := request.validate(); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	result, err := s.bookmarks.Sync(request)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("sync bookmarks: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,236 @@
+package server
+
+import (
+	"context"
+	"maps"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/tanq16/linksnapper/pkg/client"
+)
+
+// syncReplica is a minimal sync client, standing in for a browser extension
+// that mirrors the bookmark bar.
+type syncReplica struct {
+	t         *testing.T
+	api       *client.Client
+	revision  int64
+	bookmarks map[string]client.BookmarkChange
+	pending   []client.BookmarkChange
+}
+
+func newSyncReplica(t *testing.T, api *client.Client) *syncReplica {
+	return &syncReplica{t: t, api: api, bookmarks: map[string]client.BookmarkChange{}}
+}
+
+func (r *syncReplica) upsert(id, name, folder string, at time.Time) {
+	r.pending = append(r.pending, client.BookmarkChange{
+		Op: "upsert", ID: id, Folder: folder, UpdatedAt: at,
+		Bookmark: &client.BookmarkLink{Name: name, URL: "https://" + id + ".example"},
+	})
+}
+
+func (r *syncReplica) delete(id string, at time.Time) {
+	r.pending = append(r.pending, client.BookmarkChange{Op: "delete", ID: id, UpdatedAt: at})
+}
+
+func (r *syncReplica) sync() client.BookmarkSync {
+	r.t.Helper()
+	result, err := r.api.SyncBookmarks(context.Background(), client.BookmarkSyncRequest{Since: r.revision, Changes: r.pending})
+	if err != nil {
+		r.t.Fatalf("SyncBookmarks() error = %v", err)
+	}
+	r.pending = nil
+	if result.Reset {
+		clear(r.bookmarks)
+	}
+	for _, change := range result.Changes {
+		if change.Op == "delete" {
+			delete(r.bookmarks, change.ID)
+		} else {
+			r.bookmarks[change.ID] = change
+		}
+	}
+	r.revision = result.Revision
+	return result
+}
+
+func (r *syncReplica) names() map[string]string {
+	names := map[string]string{}
+	for id, change := range r.bookmarks {
+		names[id] = change.Bookmark.Name
+	}
+	return names
+}
+
+func TestBookmarkSyncReplicas(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	srv := New("", 0, store, NewHealthChecker(store, time.Hour), t.TempDir())
+	if err := srv.Setup(); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	httpServer := httptest.NewServer(srv.httpServer.Handler)
+	defer httpServer.Close()
+	ui, err := srv.bookmarks.Create("Work", BookmarkLink{Name: "Docs", URL: "https://docs.example"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	base := time.Now().Add(time.Minute)
+	a := newSyncReplica(t, client.New(httpServer.URL, nil))
+	b := newSyncReplica(t, client.New(httpServer.URL, nil))
+
+	a.upsert("news", "News", "Toolbar", base)
+	if result := a.sync(); !result.Reset || len(result.Results) != 1 || result.Results[0].Status != "applied" {
+		t.Fatalf("first sync = %+v, want a snapshot with the push applied", result)
+	}
+	if result := b.sync(); !result.Reset || !maps.Equal(b.names(), map[string]string{ui.ID: "Docs", "news": "News"}) {
+		t.Fatalf("second replica = %v after %+v, want the UI and pushed bookmarks", b.names(), result)
+	}
+
+	// Both rename the same bookmark; the later write wins whatever the order.
+	a.upsert("news", "News (A)", "Toolbar", base.Add(2*time.Second))
+	b.upsert("news", "News (B)", "Toolbar", base.Add(time.Second))
+	b.sync()
+	if result := a.sync(); result.Reset || result.Results[0].Status != "applied" {
+		t.Fatalf("later rename = %+v, want it applied as a delta", result)
+	}
+	b.upsert("news", "News (late B)", "Toolbar", base.Add(1500*time.Millisecond))
+	if result := b.sync(); result.Results[0].Status != "stale" || b.names()["news"] != "News (A)" {
+		t.Fatalf("older rename = %+v, replica has %v, want it stale and the later name pulled", result, b.names())
+	}
+
+	// A delete is a write too: an older edit does not bring the bookmark back.
+	a.delete("news", base.Add(3*time.Second))
+	a.sync()
+	b.upsert("news", "News again", "Toolbar", base.Add(2500*time.Millisecond))
+	if result := b.sync(); result.Results[0].Status != "stale" {
+		t.Fatalf("edit older than the delete = %+v, want stale", result)
+	}
+	if _, ok := b.bookmarks["news"]; ok {
+		t.Fatalf("deleted bookmark is still on the second replica")
+	}
+
+	// Edits made in the UI reach replicas as a delta for that bookmark only.
+	if _, err := srv.bookmarks.Update(ui.ID, "Work/Reference", BookmarkLink{Name: "Docs v2", URL: ui.URL}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	result := a.sync()
+	if len(result.Changes) != 1 || result.Changes[0].ID != ui.ID || result.Changes[0].Folder != "Work/Reference" {
+		t.Fatalf("pull after UI edit = %+v, want one upsert for the edited bookmark", result)
+	}
+	b.sync()
+
+	config, err := srv.bookmarks.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := map[string]string{}
+	for _, link := range bookmarkLinks(config) {
+		want[link.ID] = link.Name
+	}
+	if !maps.Equal(a.names(), want) || !maps.Equal(b.names(), want) {
+		t.Fatalf("replicas = %v and %v, want %v", a.names(), b.names(), want)
+	}
+}
+
+func TestBookmarkPull(t *testing.T) {
+	config := BookmarkConfig{Bookmarks: []BookmarkCategory{{Category: "Work", Links: []BookmarkLink{{ID: "a", Name: "A"}, {ID: "b", Name: "B"}}}}}
+	log := bookmarkSyncLog{Revision: 7, Floor: 3, Changes: []BookmarkChange{
+		{Revision: 4, Op: bookmarkChangeUpsert, ID: "a"},
+		{Revision: 5, Op: bookmarkChangeUpsert, ID: "b"},
+		{Revision: 6, Op: bookmarkChangeUpsert, ID: "a"},
+		{Revision: 7, Op: bookmarkChangeDelete, ID: "c"},
+	}}
+	tests := []struct {
+		name      string
+		since     int64
+		wantReset bool
+		want      []int64
+	}{
+		{name: "never synced", since: 0, wantReset: true, want: []int64{7, 7}},
+		{name: "trimmed from the log", since: 2, wantReset: true, want: []int64{7, 7}},
+		{name: "ahead of the server", since: 9, wantReset: true, want: []int64{7, 7}},
+		{name: "compacted to the latest per ID", since: 4, want: []int64{5, 6, 7}},
+		{name: "up to date", since: 7, want: []int64{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := pull(log, config, tt.since)
+			revisions := []int64{}
+			for _, change := range result.Changes {
+				revisions = append(revisions, change.Revision)
+			}
+			if result.Reset != tt.wantReset || result.Revision != 7 || !slices.Equal(revisions, tt.want) {
+				t.Fatalf("pull(%d) = reset %v, revisions %v, want reset %v, %v", tt.since, result.Reset, revisions, tt.wantReset, tt.want)
+			}
+		})
+	}
+}
+
+// TestBookmarkSyncStructuralChanges pushes an edit made after the bookmark
+// was created but before a change on the server. Changes to the bookmark's
+// folder or position win; changes to its siblings do not touch it.
+func TestBookmarkSyncStructuralChanges(t *testing.T) {
+	utils := "Utils"
+	tests := []struct {
+		name       string
+		change     func(store *BookmarkStore, id, sibling string) error
+		wantStatus string
+	}{
+		{name: "untouched", change: func(*BookmarkStore, string, string) error { return nil }, wantStatus: "applied"},
+		{name: "folder renamed", change: func(store *BookmarkStore, _, _ string) error {
+			_, err := store.UpdateFolder("Work/Tools", BookmarkFolderPatch{Name: &utils})
+			return err
+		}, wantStatus: "stale"},
+		{name: "bookmark moved", change: func(store *BookmarkStore, id, _ string) error {
+			return store.Move(BookmarkMove{ID: id, Folder: "Home"})
+		}, wantStatus: "stale"},
+		{name: "bookmark reordered", change: func(store *BookmarkStore, id, sibling string) error {
+			return store.Move(BookmarkMove{ID: id, Before: sibling})
+		}, wantStatus: "stale"},
+		{name: "folder deleted into its parent", change: func(store *BookmarkStore, _, _ string) error {
+			return store.DeleteFolder("Work/Tools", folderDeleteMove)
+		}, wantStatus: "stale"},
+		{name: "sibling deleted", change: func(store *BookmarkStore, _, sibling string) error {
+			return store.Delete(sibling)
+		}, wantStatus: "applied"},
+		{name: "sibling reordered", change: func(store *BookmarkStore, id, sibling string) error {
+			return store.Move(BookmarkMove{ID: sibling, After: id})
+		}, wantStatus: "applied"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewBookmarkStore(t.TempDir())
+			if _, err := store.Create("Home", BookmarkLink{Name: "Mail", URL: "https://mail.example"}); err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			sibling, err := store.Create("Work/Tools", BookmarkLink{Name: "Wiki", URL: "https://wiki.example"})
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			created, err := store.Create("Work/Tools", BookmarkLink{Name: "Docs", URL: "https://docs.example"})
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			pushedAt := created.UpdatedAt.Add(time.Nanosecond)
+			if err := tt.change(store, created.ID, sibling.ID); err != nil {
+				t.Fatalf("change error = %v", err)
+			}
+			result, err := store.Sync(BookmarkSyncRequest{Changes: []BookmarkChange{{
+				Op: bookmarkChangeUpsert, ID: created.ID, Folder: "Work/Tools", UpdatedAt: pushedAt,
+				Bookmark: &BookmarkLink{Name: "Docs (edited)", URL: "https://docs.example"},
+			}}})
+			if err != nil {
+				t.Fatalf("Sync() error = %v", err)
+			}
+			if got := result.Results[0].Status; got != tt.wantStatus {
+				t.Fatalf("Sync() status = %s, want %s", got, tt.wantStatus)
+			}
+		})
+	}
+}
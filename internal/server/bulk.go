@@ -0,0 +1,139 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+const maxBulkOperations = 1000
+
+// LinkOperation is one step of a bulk request: "delete", "move" to Path
+// (Uncategorized when empty), "state" to apply a reading state patch, or
+// "addTags" and "removeTags" to change the link's tags by Tags.
+type LinkOperation struct {
+	Op    string             `json:"op"`
+	ID    string             `json:"id"`
+	Path  []string           `json:"path,omitempty"`
+	State *ReadingStatePatch `json:"state,omitempty"`
+	Tags  []string           `json:"tags,omitempty"`
+}
+
+func (o LinkOperation) validate() error {
+	if o.ID == "" {
+		return invalidRequest("id is required")
+	}
+	switch o.Op {
+	case "delete", "move":
+		return nil
+	case "state":
+		if o.State == nil {
+			return invalidRequest("state is required for a state operation")
+		}
+		return o.State.validate()
+	case "addTags", "removeTags":
+		if len(normalizeTags(o.Tags)) == 0 {
+			return invalidRequest("tags are required for a tag operation")
+		}
+		return nil
+	}
+	return invalidRequest("op must be delete, move, state, addTags or removeTags")
+}
+
+// LinkOperationResult reports one operation. Status is "ok", "failed", or
+// "rolled_back" for an operation that succeeded but was discarded because
+// another one in an atomic request failed.
+type LinkOperationResult struct {
+	Index  int    `json:"index"`
+	Op     string `json:"op"`
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Code   string `json:"code,omitempty"`
+	Error  string `json:"error,omitempty"`
+	Link   *Link  `json:"link,omitempty"`
+}
+
+type BulkResult struct {
+	Atomic     bool                  `json:"atomic"`
+	Applied    int                   `json:"applied"`
+	Failed     int                   `json:"failed"`
+	RolledBack bool                  `json:"rolledBack"`
+	Results    []LinkOperationResult `json:"results"`
+}
+
+// applyLinkOperation changes links in place and returns the event to publish
+// once the change is saved.
+func applyLinkOperation(links *[]Link, op LinkOperation, now time.Time) (string, Link, error) {
+	if err := op.validate(); err != nil {
+		return "", Link{}, err
+	}
+	index := slices.IndexFunc(*links, func(link Link) bool {
+		return link.ID == op.ID
+	})
+	if index == -1 {
+		return "", Link{}, ErrLinkNotFound
+	}
+	link := (*links)[index]
+	switch op.Op {
+	case "delete":
+		*links = slices.Delete(*links, index, index+1)
+		return EventLinkDeleted, link, nil
+	case "move":
+		link.Path = splitLinkPath(strings.Join(op.Path, "/"))
+		if len(link.Path) == 0 {
+			link.Path = []string{"Uncategorized"}
+		}
+		link.UpdatedAt = now
+	case "state":
+		op.State.apply(&link.ReadingState, now)
+	case "addTags":
+		link.Tags = normalizeTags(slices.Concat(link.Tags, op.Tags))
+		link.UpdatedAt = now
+	case "removeTags":
+		removed := normalizeTags(op.Tags)
+		link.Tags = slices.DeleteFunc(slices.Clone(link.Tags), func(tag string) bool {
+			return slices.ContainsFunc(removed, func(other string) bool { return strings.EqualFold(tag, other) })
+		})
+		if len(link.Tags) == 0 {
+			link.Tags = nil
+		}
+		link.UpdatedAt = now
+	}
+	(*links)[index] = link
+	return EventLinkUpdated, link, nil
+}
+
+func (s *Server) handleLinksBulk(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Operations []LinkOperation `json:"operations"`
+		Atomic     bool            `json:"atomic"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, r, ErrInvalidJSON)
+		return
+	}
+	if len(request.Operations) == 0 || len(request.Operations) > maxBulkOperations {
+		writeError(w, r, invalidRequest(fmt.Sprintf("operations must hold between 1 and %d items", maxBulkOperations)))
+		return
+	}
+	result, err := s.store.BulkUpdate(request.Operations, request.Atomic)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("bulk update links: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// operationError splits an operation failure into an API error code and a
+// message.
+func operationError(err error) (string, string) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code, apiErr.Message
+	}
+	return errInternal.Code, err.Error()
+}
@@ -0,0 +1,167 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLinksBulk(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         func(ids []string) string
+		wantStatuses []string
+		wantCodes    []string
+		wantPaths    []string
+		wantStarred  bool
+		wantTags     []string
+	}{
+		{
+			name: "mixed",
+			body: func(ids []string) string {
+				return `{"operations":[
+					{"op":"move","id":"` + ids[0] + `","path":["Archive"," Old "]},
+					{"op":"delete","id":"` + ids[1] + `"},
+					{"op":"state","id":"` + ids[2] + `","state":{"readState":"unread","starred":true}},
+					{"op":"delete","id":"missing"},
+					{"op":"tag","id":"` + ids[2] + `"}
+				]}`
+			},
+			wantStatuses: []string{"ok", "ok", "ok", "failed", "failed"},
+			wantCodes:    []string{"", "", "", "link_not_found", "invalid_request"},
+			wantPaths:    []string{"Archive/Old", "Work"},
+			wantStarred:  true,
+		},
+		{
+			name: "later operations see earlier ones",
+			body: func(ids []string) string {
+				return `{"operations":[{"op":"delete","id":"` + ids[0] + `"},{"op":"move","id":"` + ids[0] + `","path":["X"]}]}`
+			},
+			wantStatuses: []string{"ok", "failed"},
+			wantCodes:    []string{"", "link_not_found"},
+			wantPaths:    []string{"Work", "Work"},
+		},
+		{
+			name: "tags",
+			body: func(ids []string) string {
+				return `{"operations":[
+					{"op":"addTags","id":"` + ids[0] + `","tags":["Go"," reference ","go"]},
+					{"op":"addTags","id":"` + ids[1] + `","tags":["go"]},
+					{"op":"removeTags","id":"` + ids[0] + `","tags":["REFERENCE","missing"]},
+					{"op":"addTags","id":"` + ids[2] + `","tags":[" "]},
+					{"op":"removeTags","id":"` + ids[1] + `","tags":["GO"]}
+				]}`
+			},
+			wantStatuses: []string{"ok", "ok", "ok", "failed", "ok"},
+			wantCodes:    []string{"", "", "", "invalid_request", ""},
+			wantPaths:    []string{"Work", "Work", "Work"},
+			wantTags:     []string{"Go", "", ""},
+		},
+		{
+			name: "move without a path",
+			body: func(ids []string) string {
+				return `{"operations":[{"op":"move","id":"` + ids[1] + `"},{"op":"move","id":"` + ids[2] + `","path":[" ","/"]}]}`
+			},
+			wantStatuses: []string{"ok", "ok"},
+			wantCodes:    []string{"", ""},
+			wantPaths:    []string{"Work", "Uncategorized", "Uncategorized"},
+		},
+		{
+			name: "atomic rolls back",
+			body: func(ids []string) string {
+				return `{"atomic":true,"operations":[{"op":"delete","id":"` + ids[0] + `"},{"op":"state","id":"` + ids[1] + `","state":{"progress":101}}]}`
+			},
+			wantStatuses: []string{"rolled_back", "failed"},
+			wantCodes:    []string{"", "invalid_request"},
+			wantPaths:    []string{"Work", "Work", "Work"},
+		},
+		{
+			name: "atomic commits",
+			body: func(ids []string) string {
+				return `{"atomic":true,"operations":[{"op":"move","id":"` + ids[0] + `","path":[]},{"op":"state","id":"` + ids[2] + `","state":{"starred":true}}]}`
+			},
+			wantStatuses: []string{"ok", "ok"},
+			wantCodes:    []string{"", ""},
+			wantPaths:    []string{"Uncategorized", "Work", "Work"},
+			wantStarred:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := NewStore(t.TempDir())
+			if err != nil {
+				t.Fatalf("NewStore() error = %v", err)
+			}
+			var ids []string
+			for _, name := range []string{"a", "b", "c"} {
+				link, err := store.AddLink(Link{URL: "https://" + name + ".example", Path: []string{"Work"}})
+				if err != nil {
+					t.Fatalf("AddLink() error = %v", err)
+				}
+				ids = append(ids, link.ID)
+			}
+			srv := New("", 0, store, NewHealthChecker(store, time.Hour), t.TempDir())
+			if err := srv.Setup(); err != nil {
+				t.Fatalf("Setup() error = %v", err)
+			}
+			rec := httptest.NewRecorder()
+			srv.httpServer.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/links/bulk", strings.NewReader(tt.body(ids))))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, body %s", rec.Code, rec.Body.String())
+			}
+			var result BulkResult
+			if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			var statuses, codes []string
+			for _, item := range result.Results {
+				statuses = append(statuses, item.Status)
+				codes = append(codes, item.Code)
+			}
+			if !slices.Equal(statuses, tt.wantStatuses) || !slices.Equal(codes, tt.wantCodes) {
+				t.Fatalf("statuses = %v codes = %v, want %v %v", statuses, codes, tt.wantStatuses, tt.wantCodes)
+			}
+			var paths []string
+			starred := false
+			for _, link := range store.GetLinks() {
+				paths = append(paths, strings.Join(link.Path, "/"))
+				starred = starred || link.Starred
+			}
+			if !slices.Equal(paths, tt.wantPaths) || starred != tt.wantStarred {
+				t.Fatalf("paths = %v starred = %v, want %v %v", paths, starred, tt.wantPaths, tt.wantStarred)
+			}
+			if tt.wantTags != nil {
+				var tags []string
+				for _, link := range store.GetLinks() {
+					tags = append(tags, strings.Join(link.Tags, ","))
+				}
+				if !slices.Equal(tags, tt.wantTags) {
+					t.Fatalf("tags = %q, want %q", tags, tt.wantTags)
+				}
+			}
+		})
+	}
+}
+
+func TestLinksBulkValidation(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	srv := New("", 0, store, NewHealthChecker(store, time.Hour), t.TempDir())
+	if err := srv.Setup(); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	for _, body := range []string{`{}`, `{"operations":[]}`, `[`} {
+		rec := httptest.NewRecorder()
+		srv.httpServer.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/links/bulk", strings.NewReader(body)))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("POST %s status = %d, want 400", body, rec.Code)
+		}
+	}
+}
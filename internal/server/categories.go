@@ -0,0 +1,97 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// PathChange reports a rename or move of a link path prefix. Merged is set
+// when links already existed under the target path.
+type PathChange struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Affected int    `json:"affected"`
+	Merged   bool   `json:"merged"`
+	DryRun   bool   `json:"dryRun"`
+}
+
+// CategoryRename renames the last segment of Path to Name.
+type CategoryRename struct {
+	Path   string `json:"path"`
+	Name   string `json:"name"`
+	Merge  bool   `json:"merge"`
+	DryRun bool   `json:"dryRun"`
+}
+
+// CategoryMove moves Path under Parent, keeping its name. An empty Parent
+// moves it to the top level.
+type CategoryMove struct {
+	Path   string `json:"path"`
+	Parent string `json:"parent"`
+	Merge  bool   `json:"merge"`
+	DryRun bool   `json:"dryRun"`
+}
+
+func (s *Server) handleCategoryRename(w http.ResponseWriter, r *http.Request) {
+	var request CategoryRename
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, r, ErrInvalidJSON)
+		return
+	}
+	from := splitLinkPath(request.Path)
+	name := strings.TrimSpace(request.Name)
+	if len(from) == 0 {
+		writeError(w, r, invalidRequest("path is required"))
+		return
+	}
+	if name == "" || strings.Contains(name, "/") {
+		writeError(w, r, invalidRequest("name must be non-empty and must not contain /"))
+		return
+	}
+	to := append(slices.Clone(from[:len(from)-1]), name)
+	s.movePath(w, r, from, to, request.Merge, request.DryRun)
+}
+
+func (s *Server) handleCategoryMove(w http.ResponseWriter, r *http.Request) {
+	var request CategoryMove
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, r, ErrInvalidJSON)
+		return
+	}
+	from := splitLinkPath(request.Path)
+	if len(from) == 0 {
+		writeError(w, r, invalidRequest("path is required"))
+		return
+	}
+	to := append(splitLinkPath(request.Parent), from[len(from)-1])
+	s.movePath(w, r, from, to, request.Merge, request.DryRun)
+}
+
+// movePath moves the links under from to to, with their category metadata.
+// The metadata is put back if the links cannot be moved, so the two files
+// never disagree after a failed request.
+func (s *Server) movePath(w http.ResponseWriter, r *http.Request, from, to []string, merge, dryRun bool) {
+	if slices.Equal(from, to) {
+		writeError(w, r, invalidRequest("path is unchanged"))
+		return
+	}
+	var change PathChange
+	moveLinks := func() (err error) {
+		change, err = s.store.MovePath(from, to, merge, dryRun)
+		return err
+	}
+	var err error
+	if dryRun {
+		err = moveLinks()
+	} else {
+		err = s.categories.Move(from, to, moveLinks)
+	}
+	if err != nil {
+		writeError(w, r, fmt.Errorf("move %s to %s: %w", strings.Join(from, "/"), strings.Join(to, "/"), err))
+		return
+	}
+	writeJSON(w, http.StatusOK, change)
+}
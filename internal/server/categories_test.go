@@ -0,0 +1,191 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCategoryRenameAndMove(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		body       string
+		wantStatus int
+		wantPaths  []string
+		want       PathChange
+	}{
+		{
+			name:       "rename",
+			target:     "/api/v1/categories/rename",
+			body:       `{"path":"Homelab","name":"Infra"}`,
+			wantStatus: http.StatusOK,
+			wantPaths:  []string{"Infra", "Infra/Media", "Infra/Media/Films", "Work", "Archive/Old"},
+			want:       PathChange{From: "Homelab", To: "Infra", Affected: 3},
+		},
+		{
+			name:       "rename nested",
+			target:     "/api/v1/categories/rename",
+			body:       `{"path":"Homelab/Media","name":"Video"}`,
+			wantStatus: http.StatusOK,
+			wantPaths:  []string{"Homelab", "Homelab/Video", "Homelab/Video/Films", "Work", "Archive/Old"},
+			want:       PathChange{From: "Homelab/Media", To: "Homelab/Video", Affected: 2},
+		},
+		{
+			name:       "dry run",
+			target:     "/api/v1/categories/rename",
+			body:       `{"path":"Homelab","name":"Infra","dryRun":true}`,
+			wantStatus: http.StatusOK,
+			wantPaths:  []string{"Homelab", "Homelab/Media", "Homelab/Media/Films", "Work", "Archive/Old"},
+			want:       PathChange{From: "Homelab", To: "Infra", Affected: 3, DryRun: true},
+		},
+		{
+			name:       "rename onto existing",
+			target:     "/api/v1/categories/rename",
+			body:       `{"path":"Homelab","name":"Work"}`,
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "rename merging",
+			target:     "/api/v1/categories/rename",
+			body:       `{"path":"Homelab","name":"Work","merge":true}`,
+			wantStatus: http.StatusOK,
+			wantPaths:  []string{"Work", "Work/Media", "Work/Media/Films", "Work", "Archive/Old"},
+			want:       PathChange{From: "Homelab", To: "Work", Affected: 3, Merged: true},
+		},
+		{
+			name:       "move",
+			target:     "/api/v1/categories/move",
+			body:       `{"path":"Homelab/Media","parent":"Archive"}`,
+			wantStatus: http.StatusOK,
+			wantPaths:  []string{"Homelab", "Archive/Media", "Archive/Media/Films", "Work", "Archive/Old"},
+			want:       PathChange{From: "Homelab/Media", To: "Archive/Media", Affected: 2},
+		},
+		{
+			name:       "move to top level",
+			target:     "/api/v1/categories/move",
+			body:       `{"path":"Archive/Old"}`,
+			wantStatus: http.StatusOK,
+			wantPaths:  []string{"Homelab", "Homelab/Media", "Homelab/Media/Films", "Work", "Old"},
+			want:       PathChange{From: "Archive/Old", To: "Old", Affected: 1},
+		},
+		{
+			name:       "move into itself",
+			target:     "/api/v1/categories/move",
+			body:       `{"path":"Homelab","parent":"Homelab/Media"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown path",
+			target:     "/api/v1/categories/move",
+			body:       `{"path":"Missing","parent":"Work"}`,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "name with slash",
+			target:     "/api/v1/categories/rename",
+			body:       `{"path":"Homelab","name":"a/b"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := NewStore(t.TempDir())
+			if err != nil {
+				t.Fatalf("NewStore() error = %v", err)
+			}
+			for i, path := range []string{"Homelab", "Homelab/Media", "Homelab/Media/Films", "Work", "Archive/Old"} {
+				if _, err := store.AddLink(Link{URL: "https://example.com/" + string(rune('a'+i)), Path: splitLinkPath(path)}); err != nil {
+					t.Fatalf("AddLink() error = %v", err)
+				}
+			}
+			srv := New("", 0, store, NewHealthChecker(store, time.Hour), t.TempDir())
+			if err := srv.Setup(); err != nil {
+				t.Fatalf("Setup() error = %v", err)
+			}
+			rec := httptest.NewRecorder()
+			srv.httpServer.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body)))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var change PathChange
+			if err := json.Unmarshal(rec.Body.Bytes(), &change); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if change != tt.want {
+				t.Fatalf("change = %+v, want %+v", change, tt.want)
+			}
+			var paths []string
+			for _, link := range store.GetLinks() {
+				paths = append(paths, strings.Join(link.Path, "/"))
+			}
+			if !slices.Equal(paths, tt.wantPaths) {
+				t.Fatalf("paths = %v, want %v", paths, tt.wantPaths)
+			}
+		})
+	}
+}
+
+// failingMoveStore fails every path move, as a failed write would, after
+// calling during.
+type failingMoveStore struct {
+	Store
+	during func()
+}
+
+func (s failingMoveStore) MovePath(from, to []string, merge, dryRun bool) (PathChange, error) {
+	s.during()
+	return PathChange{}, errors.New("disk full")
+}
+
+func TestCategoryMoveKeepsMetadataOnFailure(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	if _, err := store.AddLink(Link{URL: "https://media.example", Path: []string{"Homelab", "Media"}}); err != nil {
+		t.Fatalf("AddLink() error = %v", err)
+	}
+	// A metadata write sent while the links are being moved must survive
+	// the metadata being put back.
+	written := make(chan error, 1)
+	var srv *Server
+	during := func() {
+		go func() {
+			_, err := srv.categories.Set([]string{"Work"}, CategoryMeta{Icon: "briefcase"})
+			written <- err
+		}()
+		time.Sleep(20 * time.Millisecond)
+	}
+	srv = New("", 0, failingMoveStore{Store: store, during: during}, NewHealthChecker(store, time.Hour), t.TempDir())
+	if err := srv.Setup(); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	if _, err := srv.categories.Set([]string{"Homelab", "Media"}, CategoryMeta{Icon: "film"}); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	rec := httptest.NewRecorder()
+	srv.httpServer.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/categories/rename", strings.NewReader(`{"path":"Homelab","name":"Infra"}`)))
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("rename status = %d, want 500", rec.Code)
+	}
+	if err := <-written; err != nil {
+		t.Fatalf("Set() during the rename error = %v", err)
+	}
+	meta, err := srv.categories.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if _, ok := meta["Homelab/Media"]; !ok || meta["Work"].Icon != "briefcase" || len(meta) != 2 {
+		t.Fatalf("metadata after failed rename = %v, want Homelab/Media left in place and Work kept", meta)
+	}
+}
@@ -0,0 +1,217 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CategoryMeta holds the attributes of a resource category, which otherwise
+// only exists as a prefix of link paths. Order sorts siblings, lowest first,
+// with ties broken by name. A pinned category stays in the tree without links
+// and Collapsed is the default state of its node in the UI.
+type CategoryMeta struct {
+	Description string `json:"description,omitempty"`
+	Icon        string `json:"icon,omitempty"`
+	Color       string `json:"color,omitempty"`
+	Order       int    `json:"order,omitempty"`
+	Pinned      bool   `json:"pinned,omitempty"`
+	Collapsed   bool   `json:"collapsed,omitempty"`
+}
+
+// CategoryMetaStore keeps category metadata in categories.json, keyed by the
+// slash separated category path.
+type CategoryMetaStore struct {
+	file   string
+	mu     sync.Mutex
+	events *EventBus
+}
+
+func NewCategoryMetaStore(dataDir string) *CategoryMetaStore {
+	return &CategoryMetaStore{file: filepath.Join(dataDir, "categories.json")}
+}
+
+func (s *CategoryMetaStore) Load() (map[string]CategoryMeta, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load()
+}
+
+// Set replaces the metadata of the category at path.
+func (s *CategoryMetaStore) Set(path []string, meta CategoryMeta) (CategoryMeta, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all, err := s.load()
+	if err != nil {
+		return CategoryMeta{}, err
+	}
+	meta.Color = normalizeBookmarkColor(meta.Color)
+	key := strings.Join(path, "/")
+	all[key] = meta
+	if err := s.save(all); err != nil {
+		return CategoryMeta{}, err
+	}
+	s.events.Publish(EventCategoryUpdated, CategoryMetaEvent{Path: key, Meta: meta})
+	return meta, nil
+}
+
+func (s *CategoryMetaStore) Delete(path []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all, err := s.load()
+	if err != nil {
+		return err
+	}
+	key := strings.Join(path, "/")
+	if _, ok := all[key]; !ok {
+		return ErrCategoryNotFound
+	}
+	delete(all, key)
+	if err := s.save(all); err != nil {
+		return err
+	}
+	s.events.Publish(EventCategoryUpdated, CategoryMetaEvent{Path: key})
+	return nil
+}
+
+// Move re-keys the metadata under from to live under to and then calls
+// moveLinks to move the links themselves. Metadata already at the target wins
+// over the moved entry. If moveLinks fails, the metadata is put back; the
+// lock is held throughout, so no other metadata write can land in between
+// and be lost.
+func (s *CategoryMetaStore) Move(from, to []string, moveLinks func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, err := s.load()
+	if err != nil {
+		return fmt.Errorf("load category metadata: %w", err)
+	}
+	all := maps.Clone(previous)
+	moved := false
+	for key, meta := range previous {
+		path := splitLinkPath(key)
+		if !hasPathPrefix(path, from) {
+			continue
+		}
+		delete(all, key)
+		target := strings.Join(slices.Concat(to, path[len(from):]), "/")
+		if _, exists := all[target]; !exists {
+			all[target] = meta
+		}
+		moved = true
+	}
+	if !moved {
+		return moveLinks()
+	}
+	if err := s.save(all); err != nil {
+		return fmt.Errorf("move category metadata: %w", err)
+	}
+	if err := moveLinks(); err != nil {
+		if restoreErr := s.save(previous); restoreErr != nil {
+			return errors.Join(err, fmt.Errorf("restore category metadata: %w", restoreErr))
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *CategoryMetaStore) load() (map[string]CategoryMeta, error) {
+	all := map[string]CategoryMeta{}
+	data, err := os.ReadFile(s.file)
+	if os.IsNotExist(err) {
+		return all, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+func (s *CategoryMetaStore) save(all map[string]CategoryMeta) (err error) {
+	started := time.Now()
+	defer func() { metrics.observeStoreWrite("categories", time.Since(started), err) }()
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.file, data, 0644)
+}
+
+// applyCategoryMeta copies metadata onto the tree and sorts it. Pinned
+// categories are added when no link lives under them; other metadata for a
+// path without links is kept but not shown.
+func applyCategoryMeta(root *Category, all map[string]CategoryMeta) {
+	for key, meta := range all {
+		if meta.Pinned {
+			current := root
+			for _, segment := range splitLinkPath(key) {
+				current = current.child(segment)
+			}
+		}
+	}
+	for key, meta := range all {
+		current := root
+		for _, segment := range splitLinkPath(key) {
+			if current = current.Categories[segment]; current == nil {
+				break
+			}
+		}
+		if current != nil && current != root {
+			current.CategoryMeta = meta
+		}
+	}
+	root.sortTree()
+}
+
+func (s *Server) handleCategories(w http.ResponseWriter, r *http.Request) {
+	meta, err := s.categories.Load()
+	if err != nil {
+		writeError(w, r, fmt.Errorf("load category metadata: %w", err))
+		return
+	}
+	root := s.store.GetCategories()
+	applyCategoryMeta(root, meta)
+	writeJSON(w, http.StatusOK, root)
+}
+
+func (s *Server) handleSetCategoryMeta(w http.ResponseWriter, r *http.Request) {
+	path := splitLinkPath(r.PathValue("path"))
+	if len(path) == 0 {
+		writeError(w, r, invalidRequest("category path is required"))
+		return
+	}
+	var meta CategoryMeta
+	if err := json.NewDecoder(r.Body).Decode(&meta); err != nil {
+		writeError(w, r, ErrInvalidJSON)
+		return
+	}
+	meta, err := s.categories.Set(path, meta)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("set category metadata: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, meta)
+}
+
+func (s *Server) handleDeleteCategoryMeta(w http.ResponseWriter, r *http.Request) {
+	path := splitLinkPath(r.PathValue("path"))
+	if err := s.categories.Delete(path); err != nil {
+		writeError(w, r, fmt.Errorf("delete category metadata: %w", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
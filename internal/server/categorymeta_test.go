@@ -0,0 +1,90 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCategoryTreeWithMetadata(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	for i, seed := range []struct {
+		path   string
+		status string
+	}{{"Homelab", "healthy"}, {"Homelab/Media", "unhealthy"}, {"Homelab/Media", ""}, {"Work", "unhealthy"}} {
+		link, err := store.AddLink(Link{URL: "https://example.com/" + string(rune('a'+i)), Path: splitLinkPath(seed.path)})
+		if err != nil {
+			t.Fatalf("AddLink() error = %v", err)
+		}
+		if seed.status != "" {
+			if err := store.UpdateHealth(link.ID, Health{Status: seed.status}, time.Now()); err != nil {
+				t.Fatalf("UpdateHealth() error = %v", err)
+			}
+		}
+	}
+	srv := New("", 0, store, NewHealthChecker(store, time.Hour), t.TempDir())
+	if err := srv.Setup(); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		srv.httpServer.Handler.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return rec
+	}
+	for target, body := range map[string]string{
+		"/api/v1/categories/Homelab/Media": `{"description":"Streaming","icon":"film","color":"ctp-red","order":2,"collapsed":true}`,
+		"/api/v1/categories/Archive/Old":   `{"pinned":true}`,
+		"/api/v1/categories/Gone":          `{"description":"no links and not pinned"}`,
+		"/api/v1/categories/Work":          `{"order":-1}`,
+	} {
+		if rec := do(http.MethodPut, target, body); rec.Code != http.StatusOK {
+			t.Fatalf("PUT %s status = %d, body %s", target, rec.Code, rec.Body.String())
+		}
+	}
+
+	var root Category
+	if err := json.Unmarshal(do(http.MethodGet, "/api/v1/categories", "").Body.Bytes(), &root); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if root.Links != 4 || root.Health != (CategoryHealth{Healthy: 1, Degraded: 2, Unknown: 1}) {
+		t.Fatalf("root = %d links, health %+v", root.Links, root.Health)
+	}
+	if !slices.Equal(root.SortedCategories, []string{"Work", "Archive", "Homelab"}) {
+		t.Fatalf("sorted top-level categories = %v, want Work first by order, then by name", root.SortedCategories)
+	}
+	media := root.Categories["Homelab"].Categories["Media"]
+	if media.Links != 2 || media.Health != (CategoryHealth{Degraded: 1, Unknown: 1}) || media.Description != "Streaming" || media.Color != "red" || media.Order != 2 || !media.Collapsed {
+		t.Fatalf("Homelab/Media = %+v", media)
+	}
+	if old := root.Categories["Archive"].Categories["Old"]; old == nil || !old.Pinned || old.Links != 0 {
+		t.Fatalf("pinned Archive/Old = %+v, want an empty pinned category", old)
+	}
+	if _, ok := root.Categories["Gone"]; ok {
+		t.Fatalf("unpinned category without links is in the tree")
+	}
+
+	if rec := do(http.MethodPost, "/api/v1/categories/rename", `{"path":"Homelab","name":"Infra"}`); rec.Code != http.StatusOK {
+		t.Fatalf("rename status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	meta, err := srv.categories.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if meta["Infra/Media"].Description != "Streaming" || len(meta) != 4 {
+		t.Fatalf("metadata after rename = %+v, want Homelab/Media moved to Infra/Media", meta)
+	}
+
+	if rec := do(http.MethodDelete, "/api/v1/categories/Archive/Old", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("DELETE status = %d", rec.Code)
+	}
+	if rec := do(http.MethodDelete, "/api/v1/categories/Archive/Old", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("second DELETE status = %d, want 404", rec.Code)
+	}
+}
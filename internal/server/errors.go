@@ -0,0 +1,70 @@
+package server
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+)
+
+// APIError is an error with a stable, machine-readable code and the HTTP
+// status it is reported with. The package's sentinel errors are APIErrors,
+// so handlers can return store errors directly and callers can still match
+// them with errors.Is.
+type APIError struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+var (
+	ErrLinkNotFound      = &APIError{Status: http.StatusNotFound, Code: "link_not_found", Message: "link not found"}
+	ErrLinkExists        = &APIError{Status: http.StatusConflict, Code: "link_exists", Message: "link already exists"}
+	ErrNoRedirect        = &APIError{Status: http.StatusBadRequest, Code: "no_redirect", Message: "link has no recorded redirect"}
+	ErrRedirectUnhealthy = &APIError{Status: http.StatusConflict, Code: "redirect_unhealthy", Message: "redirect target did not pass its last health check"}
+	ErrCategoryNotFound  = &APIError{Status: http.StatusNotFound, Code: "category_not_found", Message: "category not found"}
+	ErrCategoryExists    = &APIError{Status: http.StatusConflict, Code: "category_exists", Message: "target path already has links; set merge to combine them"}
+	ErrBookmarkNotFound  = &APIError{Status: http.StatusNotFound, Code: "bookmark_not_found", Message: "bookmark not found"}
+	ErrFolderNotFound    = &APIError{Status: http.StatusNotFound, Code: "folder_not_found", Message: "bookmark folder not found"}
+	ErrFolderExists      = &APIError{Status: http.StatusConflict, Code: "folder_exists", Message: "bookmark folder already exists"}
+	ErrFolderNotEmpty    = &APIError{Status: http.StatusConflict, Code: "folder_not_empty", Message: "bookmark folder is not empty"}
+	ErrJobNotFound       = &APIError{Status: http.StatusNotFound, Code: "job_not_found", Message: "health check job not found"}
+	ErrNoSnapshot        = &APIError{Status: http.StatusNotFound, Code: "no_snapshot", Message: "no archived snapshot available"}
+	ErrInvalidJSON       = &APIError{Status: http.StatusBadRequest, Code: "invalid_json", Message: "invalid JSON body"}
+	ErrInvalidYAML       = &APIError{Status: http.StatusBadRequest, Code: "invalid_yaml", Message: "invalid YAML format"}
+	ErrNotFound          = &APIError{Status: http.StatusNotFound, Code: "not_found", Message: "not found"}
+	ErrMethodNotAllowed  = &APIError{Status: http.StatusMethodNotAllowed, Code: "method_not_allowed", Message: "method not allowed"}
+	errInternal          = &APIError{Status: http.StatusInternalServerError, Code: "internal_error", Message: "internal server error"}
+)
+
+func invalidRequest(message string) *APIError {
+	return &APIError{Status: http.StatusBadRequest, Code: "invalid_request", Message: message}
+}
+
+type errorEnvelope struct {
+	Error errorBody `json:"error"`
+}
+
+type errorBody struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// writeError reports err as a JSON error envelope. Errors that are not
+// APIErrors are logged and reported as an opaque internal error.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		slog.ErrorContext(r.Context(), "request failed", "method", r.Method, "path", r.URL.Path, "error", err)
+		apiErr = errInternal
+	}
+	writeJSON(w, apiErr.Status, errorEnvelope{Error: errorBody{
+		Code:      apiErr.Code,
+		Message:   apiErr.Message,
+		RequestID: RequestID(r.Context()),
+	}})
+}
@@ -0,0 +1,160 @@
+package server
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	EventLinkCreated           = "link.created"
+	EventLinkUpdated           = "link.updated"
+	EventLinkDeleted           = "link.deleted"
+	EventLinksImported         = "links.imported"
+	EventLinksMoved            = "links.moved"
+	EventLinkHealthChanged     = "link.health_changed"
+	EventLinkDead              = "link.dead"
+	EventHealthCheckCompleted  = "health.check_completed"
+	EventCategoryUpdated       = "category.updated"
+	EventBookmarkCreated       = "bookmark.created"
+	EventBookmarkUpdated       = "bookmark.updated"
+	EventBookmarkDeleted       = "bookmark.deleted"
+	EventBookmarksImported     = "bookmarks.imported"
+	EventBookmarksReplaced     = "bookmarks.replaced"
+	EventBookmarksReordered    = "bookmarks.reordered"
+	EventBookmarkFolderChanged = "bookmark.folder_changed"
+	EventBookmarksSynced       = "bookmarks.synced"
+)
+
+type Event struct {
+	ID   uint64    `json:"id"`
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+	Data any       `json:"data"`
+}
+
+type LinkEvent struct {
+	Link Link `json:"link"`
+}
+
+type LinkHealthEvent struct {
+	Link          Link   `json:"link"`
+	PreviousState string `json:"previousState,omitempty"`
+}
+
+type ImportEvent struct {
+	Mode  string `json:"mode"`
+	Count int    `json:"count"`
+}
+
+type BookmarkEvent struct {
+	Bookmark BookmarkLink `json:"bookmark"`
+	Folder   string       `json:"folder,omitempty"`
+}
+
+type BookmarkFolderEvent struct {
+	Action  string `json:"action"`
+	Path    string `json:"path"`
+	NewPath string `json:"newPath,omitempty"`
+}
+
+type BookmarkSyncEvent struct {
+	Revision int64 `json:"revision"`
+	Applied  int   `json:"applied"`
+}
+
+type CategoryMetaEvent struct {
+	Path string       `json:"path"`
+	Meta CategoryMeta `json:"meta"`
+}
+
+type HealthCheckEvent struct {
+	Job HealthJob `json:"job"`
+}
+
+const eventHistoryLimit = 500
+
+// EventBus fans events out to subscribers and keeps the most recent events
+// so that reconnecting clients can resume. Publishing never blocks: a
+// subscriber whose buffer is full misses the event and is marked as lagged.
+// A nil bus discards everything, so stores can be used without one.
+type EventBus struct {
+	mu          sync.Mutex
+	nextID      uint64
+	history     []Event
+	subscribers map[*Subscription]struct{}
+}
+
+type Subscription struct {
+	C      <-chan Event
+	ch     chan Event
+	bus    *EventBus
+	lagged atomic.Bool
+	once   sync.Once
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{subscribers: make(map[*Subscription]struct{})}
+}
+
+func (b *EventBus) Publish(eventType string, data any) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.nextID++
+	event := Event{ID: b.nextID, Type: eventType, Time: time.Now(), Data: data}
+	b.history = append(b.history, event)
+	if extra := len(b.history) - eventHistoryLimit; extra > 0 {
+		b.history = slices.Delete(b.history, 0, extra)
+	}
+	for subscriber := range b.subscribers {
+		select {
+		case subscriber.ch <- event:
+		default:
+			subscriber.lagged.Store(true)
+		}
+	}
+}
+
+func (b *EventBus) Subscribe(buffer int) *Subscription {
+	subscription, _, _ := b.SubscribeSince(0, buffer)
+	return subscription
+}
+
+// SubscribeSince subscribes and returns the retained events published after
+// lastID. The boolean is false when events after lastID are no longer
+// retained, in which case the caller has to resynchronise from scratch.
+func (b *EventBus) SubscribeSince(lastID uint64, buffer int) (*Subscription, []Event, bool) {
+	ch := make(chan Event, buffer)
+	subscription := &Subscription{C: ch, ch: ch, bus: b}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers[subscription] = struct{}{}
+	if lastID == 0 || lastID >= b.nextID {
+		return subscription, nil, lastID <= b.nextID
+	}
+	index := slices.IndexFunc(b.history, func(event Event) bool {
+		return event.ID > lastID
+	})
+	if index == -1 || b.history[index].ID != lastID+1 {
+		return subscription, nil, false
+	}
+	return subscription, slices.Clone(b.history[index:]), true
+}
+
+// Lagged reports whether events were dropped since the last call.
+func (s *Subscription) Lagged() bool {
+	return s.lagged.Swap(false)
+}
+
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.bus.mu.Lock()
+		delete(s.bus.subscribers, s)
+		s.bus.mu.Unlock()
+		close(s.ch)
+	})
+}
@@ -0,0 +1,268 @@
+package server
+
+import (
+	"cmp"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultFeedSize = 50
+
+// Atom, RSS and JSON Feed documents share one item list built from the
+// store; each handler only renders it.
+
+type feed struct {
+	Title   string
+	HomeURL string
+	SelfURL string
+	Updated time.Time
+	Items   []feedItem
+}
+
+type feedItem struct {
+	ID        string
+	Title     string
+	URL       string
+	Summary   string
+	Category  string
+	Published time.Time
+	Updated   time.Time
+}
+
+// buildFeed returns the newest links under the path and with the tag given
+// in the query, newest first.
+func (s *Server) buildFeed(r *http.Request) (feed, error) {
+	values := r.URL.Query()
+	query := LinkQuery{
+		Path:  splitLinkPath(values.Get("path")),
+		Tag:   strings.TrimSpace(values.Get("tag")),
+		Sort:  "created",
+		Desc:  true,
+		Limit: defaultFeedSize,
+	}
+	if value := values.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxLinkPageSize {
+			return feed{}, invalidRequest(fmt.Sprintf("limit must be between 1 and %d", maxLinkPageSize))
+		}
+		query.Limit = limit
+	}
+	links, _, _, err := query.Apply(s.store.GetLinks())
+	if err != nil {
+		return feed{}, err
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	scheme = cmp.Or(r.Header.Get("X-Forwarded-Proto"), scheme)
+	home := scheme + "://" + r.Host + "/"
+	result := feed{
+		Title:   "LinkSnapper",
+		HomeURL: home,
+		SelfURL: strings.TrimSuffix(home, "/") + r.URL.RequestURI(),
+	}
+	if len(query.Path) > 0 {
+		result.Title += ": " + strings.Join(query.Path, "/")
+	}
+	if query.Tag != "" {
+		result.Title += " #" + query.Tag
+	}
+	for _, link := range links {
+		result.Items = append(result.Items, feedItem{
+			ID:        "urn:linksnapper:link:" + url.PathEscape(link.ID),
+			Title:     cmp.Or(link.Name, link.URL),
+			URL:       link.URL,
+			Summary:   link.Description,
+			Category:  strings.Join(link.Path, "/"),
+			Published: link.CreatedAt,
+			Updated:   link.UpdatedAt,
+		})
+		if link.UpdatedAt.After(result.Updated) {
+			result.Updated = link.UpdatedAt
+		}
+	}
+	if result.Updated.IsZero() {
+		result.Updated = time.Now().UTC()
+	}
+	return result, nil
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID        string        `xml:"id"`
+	Title     string        `xml:"title"`
+	Link      atomLink      `xml:"link"`
+	Published string        `xml:"published"`
+	Updated   string        `xml:"updated"`
+	Summary   string        `xml:"summary,omitempty"`
+	Category  *atomCategory `xml:"category"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+func (s *Server) handleAtomFeed(w http.ResponseWriter, r *http.Request) {
+	f, err := s.buildFeed(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	doc := atomFeed{
+		ID:      f.SelfURL,
+		Title:   f.Title,
+		Updated: f.Updated.Format(time.RFC3339),
+		Links:   []atomLink{{Href: f.SelfURL, Rel: "self"}, {Href: f.HomeURL}},
+	}
+	for _, item := range f.Items {
+		entry := atomEntry{
+			ID:        item.ID,
+			Title:     item.Title,
+			Link:      atomLink{Href: item.URL},
+			Published: item.Published.Format(time.RFC3339),
+			Updated:   item.Updated.Format(time.RFC3339),
+			Summary:   item.Summary,
+		}
+		if item.Category != "" {
+			entry.Category = &atomCategory{Term: item.Category}
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+	writeXML(w, "application/atom+xml", doc)
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	GUID        rssGUID `xml:"guid"`
+	Description string  `xml:"description,omitempty"`
+	Category    string  `xml:"category,omitempty"`
+	PubDate     string  `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+func (s *Server) handleRSSFeed(w http.ResponseWriter, r *http.Request) {
+	f, err := s.buildFeed(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	doc := rssFeed{Version: "2.0", Channel: rssChannel{
+		Title:         f.Title,
+		Link:          f.HomeURL,
+		Description:   "Newest links in " + f.Title,
+		LastBuildDate: f.Updated.Format(time.RFC1123Z),
+	}}
+	for _, item := range f.Items {
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:       item.Title,
+			Link:        item.URL,
+			GUID:        rssGUID{Value: item.ID},
+			Description: item.Summary,
+			Category:    item.Category,
+			PubDate:     item.Published.Format(time.RFC1123Z),
+		})
+	}
+	writeXML(w, "application/rss+xml", doc)
+}
+
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string    `json:"id"`
+	URL           string    `json:"url"`
+	Title         string    `json:"title"`
+	ContentText   string    `json:"content_text"`
+	DatePublished time.Time `json:"date_published"`
+	DateModified  time.Time `json:"date_modified"`
+	Tags          []string  `json:"tags,omitempty"`
+}
+
+func (s *Server) handleJSONFeed(w http.ResponseWriter, r *http.Request) {
+	f, err := s.buildFeed(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	doc := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		HomePageURL: f.HomeURL,
+		FeedURL:     f.SelfURL,
+		Items:       []jsonFeedItem{},
+	}
+	for _, item := range f.Items {
+		entry := jsonFeedItem{
+			ID:            item.ID,
+			URL:           item.URL,
+			Title:         item.Title,
+			ContentText:   cmp.Or(item.Summary, item.URL),
+			DatePublished: item.Published,
+			DateModified:  item.Updated,
+		}
+		if item.Category != "" {
+			entry.Tags = []string{item.Category}
+		}
+		doc.Items = append(doc.Items, entry)
+	}
+	w.Header().Set("Content-Type", "application/feed+json")
+	if err := json.NewEncoder(w).Encode(doc); err != nil {
+		slog.Error("failed to encode JSON feed", "error", err)
+	}
+}
+
+func writeXML(w http.ResponseWriter, contentType string, value any) {
+	w.Header().Set("Content-Type", contentType+"; charset=utf-8")
+	w.Write([]byte(xml.Header))
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		slog.Error("failed to encode XML response", "error", err)
+	}
+}
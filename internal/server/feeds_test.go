@@ -0,0 +1,92 @@
+package server
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLinkFeeds(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	base := time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC)
+	err = store.ImportLinks([]Link{
+		{ID: "a", URL: "https://a.example", Name: "Alpha", Path: []string{"Reading", "Security"}, CreatedAt: base},
+		{ID: "b", URL: "https://b.example", Path: []string{"Reading", "Go"}, Tags: []string{"talks"}, CreatedAt: base.Add(time.Hour)},
+		{ID: "c", URL: "https://c.example", Name: "Charlie & Co", Description: "Threat models", Path: []string{"Reading", "Security", "Web"}, Tags: []string{"Talks"}, CreatedAt: base.Add(2 * time.Hour)},
+	}, "replace")
+	if err != nil {
+		t.Fatalf("ImportLinks() error = %v", err)
+	}
+	srv := New("", 0, store, NewHealthChecker(store, time.Hour), t.TempDir())
+	if err := srv.Setup(); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	get := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		srv.httpServer.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s status = %d, body %s", target, rec.Code, rec.Body.String())
+		}
+		return rec
+	}
+
+	rec := get("/feeds/links.atom?path=Reading/Security")
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "application/atom+xml") {
+		t.Fatalf("Atom Content-Type = %q", got)
+	}
+	var atom atomFeed
+	if err := xml.Unmarshal(rec.Body.Bytes(), &atom); err != nil {
+		t.Fatalf("Unmarshal(atom) error = %v", err)
+	}
+	var titles []string
+	for _, entry := range atom.Entries {
+		titles = append(titles, entry.Title)
+	}
+	if atom.Title != "LinkSnapper: Reading/Security" || !slices.Equal(titles, []string{"Charlie & Co", "Alpha"}) {
+		t.Fatalf("Atom feed %q entries = %v, want newest first under Reading/Security", atom.Title, titles)
+	}
+	if atom.Updated != base.Add(2*time.Hour).Format(time.RFC3339) {
+		t.Fatalf("Atom updated = %s", atom.Updated)
+	}
+
+	var rss rssFeed
+	if err := xml.Unmarshal(get("/feeds/links.rss?limit=1").Body.Bytes(), &rss); err != nil {
+		t.Fatalf("Unmarshal(rss) error = %v", err)
+	}
+	if len(rss.Channel.Items) != 1 || rss.Channel.Items[0].Link != "https://c.example" || rss.Channel.Items[0].Category != "Reading/Security/Web" {
+		t.Fatalf("RSS items = %+v, want only the newest link", rss.Channel.Items)
+	}
+
+	var jf jsonFeed
+	if err := json.Unmarshal(get("/feeds/links.json").Body.Bytes(), &jf); err != nil {
+		t.Fatalf("Unmarshal(json feed) error = %v", err)
+	}
+	if len(jf.Items) != 3 || jf.Items[1].Title != "https://b.example" || jf.Items[1].ContentText != "https://b.example" {
+		t.Fatalf("JSON feed items = %+v", jf.Items)
+	}
+	if jf.FeedURL != "http://example.com/feeds/links.json" {
+		t.Fatalf("JSON feed_url = %q", jf.FeedURL)
+	}
+
+	atom = atomFeed{}
+	if err := xml.Unmarshal(get("/feeds/links.atom?path=Reading/Security&tag=talks").Body.Bytes(), &atom); err != nil {
+		t.Fatalf("Unmarshal(tagged atom) error = %v", err)
+	}
+	if atom.Title != "LinkSnapper: Reading/Security #talks" || len(atom.Entries) != 1 || atom.Entries[0].Link.Href != "https://c.example" {
+		t.Fatalf("tagged Atom feed %q entries = %+v, want only Charlie & Co", atom.Title, atom.Entries)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.httpServer.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/feeds/links.atom?limit=0", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid limit status = %d, want 400", rec.Code)
+	}
+}
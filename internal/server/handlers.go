@@ -4,127 +4,211 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
-	"log"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
-	"net/url"
 	"slices"
-	"strings"
+	"strconv"
 )
 
-func (s *Server) handleLinks(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		links := s.store.GetLinks()
-		writeJSON(w, http.StatusOK, links)
-
-	case http.MethodPost:
-		var link Link
-		if err := json.NewDecoder(r.Body).Decode(&link); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
-		}
-		parsedURL, err := url.Parse(link.URL)
-		if err != nil {
-			http.Error(w, "Invalid URL format", http.StatusBadRequest)
-			return
-		}
-		parsedURL.RawQuery = ""
-		parsedURL.Fragment = ""
-		link.URL = parsedURL.String()
-		if len(link.Path) == 0 {
-			link.Path = []string{"Uncategorized"}
-		}
-		created, err := s.store.AddLink(link)
-		if err != nil {
-			if err.Error() == "link already exists" {
-				http.Error(w, err.Error(), http.StatusConflict)
-			} else {
-				log.Printf("ERROR Failed to add link: %v", err)
-				http.Error(w, err.Error(), http.StatusInternalServerError)
-			}
-			return
-		}
-		writeJSON(w, http.StatusCreated, created)
-	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+// handleListLinks returns a page of links as a JSON array. Paging metadata
+// travels in headers so that clients expecting a plain array keep working.
+func (s *Server) handleListLinks(w http.ResponseWriter, r *http.Request) {
+	query, err := parseLinkQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	links, total, next, err := query.Apply(s.store.GetLinks())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	if next != "" {
+		values := r.URL.Query()
+		values.Set("cursor", next)
+		w.Header().Set("X-Next-Cursor", next)
+		w.Header().Set("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, r.URL.Path, values.Encode()))
+	}
+	if len(query.Fields) == 0 {
+		writeJSONWithETag(w, r, http.StatusOK, links)
+		return
+	}
+	selected, err := selectFields(links, query.Fields)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("select link fields: %w", err))
+		return
 	}
+	writeJSONWithETag(w, r, http.StatusOK, selected)
 }
 
-func (s *Server) handleCategories(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		categories := s.store.GetCategories()
-		writeJSON(w, http.StatusOK, categories)
-	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+func (s *Server) handleCreateLink(w http.ResponseWriter, r *http.Request) {
+	var link Link
+	if err := json.NewDecoder(r.Body).Decode(&link); err != nil {
+		writeError(w, r, ErrInvalidJSON)
+		return
+	}
+	normalized, err := s.normalizer.Normalize(link.URL)
+	if err != nil {
+		writeError(w, r, invalidRequest("invalid URL format"))
+		return
+	}
+	link.URL = normalized
+	if len(link.Path) == 0 {
+		link.Path = []string{"Uncategorized"}
 	}
+	created, err := s.store.AddLink(link)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("add link: %w", err))
+		return
+	}
+	s.health.StartJob([]Link{created}, nil, []string{created.ID})
+	writeJSON(w, http.StatusCreated, created)
 }
 
 func (s *Server) handleLinkDelete(w http.ResponseWriter, r *http.Request) {
-	id := strings.TrimPrefix(r.URL.Path, "/api/links/")
-	if id == "" {
-		http.Error(w, "Invalid link ID", http.StatusBadRequest)
-		return
-	}
+	id := r.PathValue("id")
 	if err := s.store.DeleteLink(id); err != nil {
-		if errors.Is(err, ErrLinkNotFound) {
-			http.Error(w, err.Error(), http.StatusNotFound)
-			return
-		}
-		log.Printf("ERROR Failed to delete link %s: %v", id, err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, fmt.Errorf("delete link %s: %w", id, err))
 		return
 	}
 	w.WriteHeader(http.StatusOK)
 }
 
 func (s *Server) handleLinkEdit(w http.ResponseWriter, r *http.Request) {
-	id := strings.TrimPrefix(r.URL.Path, "/api/links/")
-	if id == "" {
-		http.Error(w, "Invalid link ID", http.StatusBadRequest)
-		return
-	}
+	id := r.PathValue("id")
 	var updatedLink Link
 	if err := json.NewDecoder(r.Body).Decode(&updatedLink); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, ErrInvalidJSON)
 		return
 	}
 	if err := s.store.UpdateLink(id, updatedLink); err != nil {
-		if errors.Is(err, ErrLinkNotFound) {
-			http.Error(w, err.Error(), http.StatusNotFound)
-			return
-		}
-		log.Printf("ERROR Failed to update link %s: %v", id, err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, fmt.Errorf("update link %s: %w", id, err))
+		return
+	}
+	link, ok := s.findLink(id)
+	if !ok {
+		writeError(w, r, fmt.Errorf("updated link %s was not found", id))
 		return
 	}
+	writeJSON(w, http.StatusOK, link)
+}
+
+func (s *Server) findLink(id string) (Link, bool) {
 	links := s.store.GetLinks()
 	index := slices.IndexFunc(links, func(link Link) bool {
 		return link.ID == id
 	})
 	if index == -1 {
-		log.Printf("ERROR Updated link %s was not found", id)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return Link{}, false
+	}
+	return links[index], true
+}
+
+func (s *Server) handleLinkHealth(w http.ResponseWriter, r *http.Request) {
+	link, ok := s.findLink(r.PathValue("id"))
+	if !ok {
+		writeError(w, r, ErrLinkNotFound)
 		return
 	}
-	writeJSON(w, http.StatusOK, links[index])
+	history := link.HealthHistory
+	if history == nil {
+		history = []HealthRecord{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"id":          link.ID,
+		"url":         link.URL,
+		"health":      link.Health,
+		"lastChecked": link.LastChecked,
+		"history":     history,
+	})
 }
 
-func (s *Server) handleLinksImport(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+func (s *Server) handleLinkCheck(w http.ResponseWriter, r *http.Request) {
+	link, ok := s.findLink(r.PathValue("id"))
+	if !ok {
+		writeError(w, r, ErrLinkNotFound)
+		return
+	}
+	job := s.health.StartJob([]Link{link}, nil, []string{link.ID})
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+func (s *Server) handleHealthJob(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.health.Job(r.PathValue("id"))
+	if !ok {
+		writeError(w, r, ErrJobNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+func (s *Server) handleHealthCheck(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Path []string `json:"path"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, r, ErrInvalidJSON)
 		return
 	}
+	if value := r.URL.Query().Get("path"); value != "" {
+		request.Path = splitLinkPath(value)
+	}
+	links := slices.DeleteFunc(s.store.GetLinks(), func(link Link) bool {
+		return !hasPathPrefix(link.Path, request.Path)
+	})
+	job := s.health.StartJob(links, request.Path, nil)
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+func (s *Server) handleLinkApplyRedirect(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	link, err := s.store.ApplyRedirect(id)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("apply redirect for link %s: %w", id, err))
+		return
+	}
+	writeJSON(w, http.StatusOK, link)
+}
+
+func (s *Server) handleApplyRedirects(w http.ResponseWriter, r *http.Request) {
+	type skipped struct {
+		ID    string `json:"id"`
+		URL   string `json:"url"`
+		Error string `json:"error"`
+	}
+	applied := []Link{}
+	skips := []skipped{}
+	for _, link := range s.store.GetLinks() {
+		if !link.Health.MovedPermanently {
+			continue
+		}
+		updated, err := s.store.ApplyRedirect(link.ID)
+		if err != nil {
+			skips = append(skips, skipped{ID: link.ID, URL: link.URL, Error: err.Error()})
+			continue
+		}
+		applied = append(applied, updated)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"applied": applied,
+		"skipped": skips,
+	})
+}
+
+func (s *Server) handleLinksImport(w http.ResponseWriter, r *http.Request) {
 	var raw json.RawMessage
 	if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
-		http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+		writeError(w, r, ErrInvalidJSON)
 		return
 	}
 	var links []Link
 	mode := r.URL.Query().Get("mode")
 	if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
 		if err := json.Unmarshal(raw, &links); err != nil {
-			http.Error(w, "Invalid links import", http.StatusBadRequest)
+			writeError(w, r, invalidRequest("invalid links import"))
 			return
 		}
 	} else {
@@ -133,11 +217,11 @@ func (s *Server) handleLinksImport(w http.ResponseWriter, r *http.Request) {
 			Mode  string  `json:"mode"`
 		}
 		if err := json.Unmarshal(raw, &envelope); err != nil {
-			http.Error(w, "Invalid links import", http.StatusBadRequest)
+			writeError(w, r, invalidRequest("invalid links import"))
 			return
 		}
 		if envelope.Links == nil {
-			http.Error(w, "links field is required", http.StatusBadRequest)
+			writeError(w, r, invalidRequest("links field is required"))
 			return
 		}
 		links = *envelope.Links
@@ -149,12 +233,11 @@ func (s *Server) handleLinksImport(w http.ResponseWriter, r *http.Request) {
 		mode = "merge"
 	}
 	if mode != "merge" && mode != "replace" {
-		http.Error(w, "mode must be merge or replace", http.StatusBadRequest)
+		writeError(w, r, invalidRequest("mode must be merge or replace"))
 		return
 	}
 	if err := s.store.ImportLinks(links, mode); err != nil {
-		log.Printf("ERROR Failed to import links: %v", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		writeError(w, r, fmt.Errorf("import links: %w", err))
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -164,6 +247,6 @@ func writeJSON(w http.ResponseWriter, status int, value any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	if err := json.NewEncoder(w).Encode(value); err != nil {
-		log.Printf("ERROR Failed to encode JSON response: %v", err)
+		slog.Error("failed to encode JSON response", "error", err)
 	}
 }
@@ -1,18 +1,43 @@
 package server
 
 import (
-	"log"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
 	"sync"
 	"time"
 )
 
+const (
+	maxRedirects       = 10
+	healthHistoryLimit = 20
+	deadAfterFailures  = 3
+	deadAfterPeriod    = 7 * 24 * time.Hour
+)
+
+// errTooManyRedirects reports a redirect chain longer than maxRedirects,
+// usually a loop.
+var errTooManyRedirects = errors.New("too many redirects")
+
 type HealthChecker struct {
-	store    Store
-	client   *http.Client
-	interval time.Duration
-	running  bool
-	mu       sync.Mutex
+	store     Store
+	client    *http.Client
+	interval  time.Duration
+	running   bool
+	mu        sync.Mutex
+	semaphore chan struct{} // shared by every job, so overlapping jobs stay within the limit
+	perHost   int
+	retries   int
+	backoff   time.Duration
+	maxWait   time.Duration
+	hosts     map[string]chan struct{}
+	hostsMu   sync.Mutex
+	jobs      *healthJobs
 }
 
 func NewHealthChecker(store Store, interval time.Duration) *HealthChecker {
@@ -21,13 +46,17 @@ func NewHealthChecker(store Store, interval time.Duration) *HealthChecker {
 		client: &http.Client{
 			Timeout: 10 * time.Second,
 			CheckRedirect: func(req *http.Request, via []*http.Request) error {
-				if len(via) >= 10 {
-					return http.ErrUseLastResponse
-				}
-				return nil
+				return http.ErrUseLastResponse
 			},
 		},
-		interval: interval,
+		interval:  interval,
+		semaphore: make(chan struct{}, 10),
+		perHost:   2,
+		retries:   2,
+		backoff:   time.Second,
+		maxWait:   30 * time.Second,
+		hosts:     make(map[string]chan struct{}),
+		jobs:      newHealthJobs(),
 	}
 }
 
@@ -60,48 +89,200 @@ func (hc *HealthChecker) Stop() {
 
 func (hc *HealthChecker) CheckAllLinks() {
 	links := hc.store.GetLinks()
+	hc.runJob(hc.jobs.start(len(links), nil, nil), links)
+}
+
+func (hc *HealthChecker) checkLinks(links []Link, jobID string) {
 	var wg sync.WaitGroup
-	semaphore := make(chan struct{}, 10)
 	for i := range links {
 		wg.Go(func() {
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
-			link := &links[i]
+			link := links[i]
+			release := hc.acquireHost(link.URL)
+			defer release()
+			hc.semaphore <- struct{}{}
+			defer func() { <-hc.semaphore }()
 			health := hc.CheckLink(link.URL)
-			link.Health = health
-			link.LastChecked = time.Now()
-			if err := hc.store.UpdateLink(link.ID, *link); err != nil {
-				log.Printf("ERROR Failed to update link health status for %s: %v", link.URL, err)
+			if err := hc.store.UpdateHealth(link.ID, health, time.Now()); err != nil {
+				slog.Error("failed to update link health", "job_id", jobID, "link_id", link.ID, "url", link.URL, "error", err)
 			}
+			hc.jobs.record(jobID, health)
 		})
 	}
 	wg.Wait()
 }
 
-func (hc *HealthChecker) CheckLink(url string) Health {
-	req, err := http.NewRequest("HEAD", url, nil)
-	if err != nil {
+// CheckLink runs the check pipeline for a single URL: a HEAD request, a
+// ranged GET when HEAD is refused, and retries with backoff while the
+// server reports a transient failure.
+func (hc *HealthChecker) CheckLink(rawURL string) Health {
+	if _, err := url.ParseRequestURI(rawURL); err != nil {
 		return Health{
 			Status: "unhealthy",
 			Error:  "Invalid URL",
 		}
 	}
-	resp, err := hc.client.Do(req)
-	if err != nil {
-		return Health{
-			Status: "unhealthy",
-			Error:  err.Error(),
+	var health Health
+	for attempt := 0; ; attempt++ {
+		result, wait, retry := hc.probe(rawURL)
+		health = result
+		health.Attempts = attempt + 1
+		if !retry || attempt >= hc.retries {
+			return health
+		}
+		if wait <= 0 {
+			wait = hc.backoff << attempt
 		}
+		time.Sleep(min(wait, hc.maxWait))
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode >= 200 && resp.StatusCode < 400 {
-		return Health{
-			Status:     "healthy",
+}
+
+func (hc *HealthChecker) probe(rawURL string) (Health, time.Duration, bool) {
+	var health Health
+	for _, method := range []string{http.MethodHead, http.MethodGet} {
+		resp, redirects, err := hc.follow(method, rawURL)
+		if err != nil {
+			health = Health{
+				Status:    "unhealthy",
+				Method:    method,
+				Error:     err.Error(),
+				Redirects: redirects,
+			}
+			if isTimeout(err) {
+				return health, 0, true
+			}
+			continue
+		}
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+		health = Health{
+			Status:     "unhealthy",
 			StatusCode: resp.StatusCode,
+			Method:     method,
+			Redirects:  redirects,
+		}
+		if healthyStatus(method, resp.StatusCode) {
+			health.Status = "healthy"
+			// Only a redirect chain that ends on a working page is worth
+			// offering as the link's new URL.
+			if len(redirects) > 0 {
+				health.FinalURL = redirects[len(redirects)-1].URL
+				health.MovedPermanently = !slices.ContainsFunc(redirects, func(redirect Redirect) bool {
+					return redirect.StatusCode != http.StatusMovedPermanently && redirect.StatusCode != http.StatusPermanentRedirect
+				})
+			}
+			return health, 0, false
+		}
+		if method == http.MethodHead {
+			continue
+		}
+		if retryableStatus(resp.StatusCode) {
+			return health, retryAfter(resp.Header.Get("Retry-After")), true
+		}
+	}
+	return health, 0, false
+}
+
+// follow sends a request and follows up to maxRedirects redirects by hand so
+// that every hop can be recorded. A redirect without a Location comes back
+// as the response; a longer chain is an error.
+func (hc *HealthChecker) follow(method, rawURL string) (*http.Response, []Redirect, error) {
+	var redirects []Redirect
+	current := rawURL
+	for {
+		req, err := http.NewRequest(method, current, nil)
+		if err != nil {
+			return nil, redirects, err
+		}
+		if method == http.MethodGet {
+			req.Header.Set("Range", "bytes=0-0")
+		}
+		resp, err := hc.client.Do(req)
+		if err != nil {
+			return nil, redirects, err
 		}
+		location := resp.Header.Get("Location")
+		if resp.StatusCode < 300 || resp.StatusCode >= 400 || location == "" {
+			return resp, redirects, nil
+		}
+		next, err := resp.Request.URL.Parse(location)
+		if err != nil {
+			return resp, redirects, nil
+		}
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+		if len(redirects) >= maxRedirects {
+			return nil, redirects, errTooManyRedirects
+		}
+		current = next.String()
+		redirects = append(redirects, Redirect{URL: current, StatusCode: resp.StatusCode})
+	}
+}
+
+// deriveHealthState classifies a link from its check history. A failing link
+// is only considered dead once it has failed deadAfterFailures times in a row
+// across at least deadAfterPeriod; anything short of that is degraded.
+func deriveHealthState(history []HealthRecord) string {
+	if len(history) == 0 {
+		return ""
+	}
+	latest := history[len(history)-1]
+	if latest.Status == "healthy" {
+		return "healthy"
+	}
+	failures := 0
+	firstFailure := latest.CheckedAt
+	for i := len(history) - 1; i >= 0 && history[i].Status != "healthy"; i-- {
+		failures++
+		firstFailure = history[i].CheckedAt
+	}
+	if failures >= deadAfterFailures && latest.CheckedAt.Sub(firstFailure) >= deadAfterPeriod {
+		return "dead"
+	}
+	return "degraded"
+}
+
+// acquireHost blocks until the host of rawURL has a free check slot and
+// returns the function that releases it.
+func (hc *HealthChecker) acquireHost(rawURL string) func() {
+	host := linkHost(rawURL)
+	hc.hostsMu.Lock()
+	slots, ok := hc.hosts[host]
+	if !ok {
+		slots = make(chan struct{}, max(hc.perHost, 1))
+		hc.hosts[host] = slots
+	}
+	hc.hostsMu.Unlock()
+	slots <- struct{}{}
+	return func() { <-slots }
+}
+
+// healthyStatus reports whether a final response means the link works. A
+// redirect only ends up here when it cannot be followed, so it does not.
+func healthyStatus(method string, code int) bool {
+	if code >= 200 && code < 300 {
+		return true
+	}
+	return method == http.MethodGet && code == http.StatusRequestedRangeNotSatisfiable
+}
+
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func retryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second
 	}
-	return Health{
-		Status:     "unhealthy",
-		StatusCode: resp.StatusCode,
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0)
 	}
+	return 0
 }
@@ -0,0 +1,435 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestHealthChecker(t *testing.T) *HealthChecker {
+	t.Helper()
+	storeValue, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	hc := NewHealthChecker(storeValue, time.Hour)
+	hc.client.Timeout = 200 * time.Millisecond
+	hc.backoff = time.Millisecond
+	hc.maxWait = 50 * time.Millisecond
+	return hc
+}
+
+func TestCheckLinkStrategies(t *testing.T) {
+	tests := []struct {
+		name         string
+		handler      func(calls int, w http.ResponseWriter, r *http.Request)
+		wantStatus   string
+		wantCode     int
+		wantMethod   string
+		wantAttempts int
+	}{
+		{
+			name: "head ok",
+			handler: func(calls int, w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			},
+			wantStatus: "healthy", wantCode: http.StatusOK, wantMethod: http.MethodHead, wantAttempts: 1,
+		},
+		{
+			name: "head refused falls back to ranged get",
+			handler: func(calls int, w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodHead {
+					w.WriteHeader(http.StatusMethodNotAllowed)
+					return
+				}
+				if r.Header.Get("Range") != "bytes=0-0" {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				w.WriteHeader(http.StatusPartialContent)
+			},
+			wantStatus: "healthy", wantCode: http.StatusPartialContent, wantMethod: http.MethodGet, wantAttempts: 1,
+		},
+		{
+			name: "not found is not retried",
+			handler: func(calls int, w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			wantStatus: "unhealthy", wantCode: http.StatusNotFound, wantMethod: http.MethodGet, wantAttempts: 1,
+		},
+		{
+			name: "server error retried until success",
+			handler: func(calls int, w http.ResponseWriter, r *http.Request) {
+				if calls <= 2 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			},
+			wantStatus: "healthy", wantCode: http.StatusOK, wantMethod: http.MethodHead, wantAttempts: 2,
+		},
+		{
+			name: "persistent server error gives up",
+			handler: func(calls int, w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+			},
+			wantStatus: "unhealthy", wantCode: http.StatusBadGateway, wantMethod: http.MethodGet, wantAttempts: 3,
+		},
+		{
+			name: "timeout retried",
+			handler: func(calls int, w http.ResponseWriter, r *http.Request) {
+				if calls == 1 {
+					time.Sleep(400 * time.Millisecond)
+				}
+				w.WriteHeader(http.StatusOK)
+			},
+			wantStatus: "healthy", wantCode: http.StatusOK, wantMethod: http.MethodHead, wantAttempts: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tt.handler(int(calls.Add(1)), w, r)
+			}))
+			defer server.Close()
+			hc := newTestHealthChecker(t)
+			got := hc.CheckLink(server.URL)
+			if got.Status != tt.wantStatus || got.StatusCode != tt.wantCode {
+				t.Fatalf("CheckLink() = %#v, want %s/%d", got, tt.wantStatus, tt.wantCode)
+			}
+			if got.Method != tt.wantMethod || got.Attempts != tt.wantAttempts {
+				t.Fatalf("CheckLink() method/attempts = %s/%d, want %s/%d", got.Method, got.Attempts, tt.wantMethod, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestCheckLinkHonoursRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	var first time.Time
+	var waited time.Duration
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			first = time.Now()
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			waited = time.Since(first)
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+	hc := newTestHealthChecker(t)
+	hc.maxWait = 5 * time.Second
+	got := hc.CheckLink(server.URL)
+	if got.Status != "healthy" {
+		t.Fatalf("CheckLink() = %#v, want healthy", got)
+	}
+	if waited < 900*time.Millisecond {
+		t.Fatalf("retry waited %v, want Retry-After of 1s", waited)
+	}
+}
+
+func TestCheckAllLinksPerHostConcurrency(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			seen := peak.Load()
+			if current <= seen || peak.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	hc := newTestHealthChecker(t)
+	for i := range 8 {
+		if _, err := hc.store.AddLink(Link{URL: server.URL + "/" + string(rune('a'+i))}); err != nil {
+			t.Fatalf("AddLink() error = %v", err)
+		}
+	}
+	hc.CheckAllLinks()
+	if got := peak.Load(); got > int32(hc.perHost) {
+		t.Fatalf("peak in-flight requests = %d, want at most %d", got, hc.perHost)
+	}
+	for _, link := range hc.store.GetLinks() {
+		if link.Health.Status != "healthy" {
+			t.Fatalf("link %s health = %#v, want healthy", link.URL, link.Health)
+		}
+	}
+}
+
+func TestHealthJobsShareConcurrencyLimit(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			seen := peak.Load()
+			if current <= seen || peak.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	hc := newTestHealthChecker(t)
+	hc.semaphore = make(chan struct{}, 1)
+	for i := range 4 {
+		if _, err := hc.store.AddLink(Link{URL: server.URL + "/" + string(rune('a'+i))}); err != nil {
+			t.Fatalf("AddLink() error = %v", err)
+		}
+	}
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Go(hc.CheckAllLinks)
+	}
+	wg.Wait()
+	if got := peak.Load(); got > 1 {
+		t.Fatalf("peak in-flight requests across jobs = %d, want at most 1", got)
+	}
+}
+
+func TestRetryAfterParsing(t *testing.T) {
+	if got := retryAfter("3"); got != 3*time.Second {
+		t.Fatalf("retryAfter(3) = %v, want 3s", got)
+	}
+	if got := retryAfter(time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)); got != 0 {
+		t.Fatalf("retryAfter(past date) = %v, want 0", got)
+	}
+	if got := retryAfter("soon"); got != 0 {
+		t.Fatalf("retryAfter(invalid) = %v, want 0", got)
+	}
+}
+
+func TestDeriveHealthState(t *testing.T) {
+	start := time.Date(2026, time.August, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	record := func(status string, offset time.Duration) HealthRecord {
+		return HealthRecord{Status: status, CheckedAt: start.Add(offset)}
+	}
+	tests := []struct {
+		name    string
+		history []HealthRecord
+		want    string
+	}{
+		{name: "no history", want: ""},
+		{name: "healthy", history: []HealthRecord{record("unhealthy", 0), record("healthy", 2*day)}, want: "healthy"},
+		{name: "single blip", history: []HealthRecord{record("healthy", 0), record("unhealthy", 2*day)}, want: "degraded"},
+		{name: "many failures in a short window", history: []HealthRecord{record("unhealthy", 0), record("unhealthy", day), record("unhealthy", 2*day)}, want: "degraded"},
+		{name: "long failure streak", history: []HealthRecord{record("unhealthy", 0), record("unhealthy", 4*day), record("unhealthy", 8*day)}, want: "dead"},
+		{name: "recovery resets streak", history: []HealthRecord{record("unhealthy", 0), record("healthy", 4*day), record("unhealthy", 6*day), record("unhealthy", 8*day)}, want: "degraded"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := deriveHealthState(tt.history); got != tt.want {
+				t.Fatalf("deriveHealthState() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateHealthHistory(t *testing.T) {
+	hc := newTestHealthChecker(t)
+	link, err := hc.store.AddLink(Link{URL: "https://example.com", Name: "Example"})
+	if err != nil {
+		t.Fatalf("AddLink() error = %v", err)
+	}
+	start := time.Now()
+	for i := range healthHistoryLimit + 5 {
+		health := Health{Status: "unhealthy", StatusCode: http.StatusNotFound}
+		if err := hc.store.UpdateHealth(link.ID, health, start.Add(time.Duration(i)*24*time.Hour)); err != nil {
+			t.Fatalf("UpdateHealth() error = %v", err)
+		}
+	}
+	got := hc.store.GetLinks()[0]
+	if len(got.HealthHistory) != healthHistoryLimit {
+		t.Fatalf("history length = %d, want %d", len(got.HealthHistory), healthHistoryLimit)
+	}
+	if got.Health.State != "dead" || got.Name != "Example" {
+		t.Fatalf("link after checks = %#v, want dead state and preserved name", got)
+	}
+}
+
+// Run with -race: copies handed out by GetLinks must not share the history
+// that UpdateHealth trims.
+func TestUpdateHealthHistoryCopies(t *testing.T) {
+	hc := newTestHealthChecker(t)
+	link, err := hc.store.AddLink(Link{URL: "https://example.com"})
+	if err != nil {
+		t.Fatalf("AddLink() error = %v", err)
+	}
+	start := time.Now()
+	for i := range healthHistoryLimit {
+		if err := hc.store.UpdateHealth(link.ID, Health{Status: "healthy"}, start.Add(time.Duration(i)*time.Hour)); err != nil {
+			t.Fatalf("UpdateHealth() error = %v", err)
+		}
+	}
+	snapshot := hc.store.GetLinks()[0].HealthHistory
+	want := slices.Clone(snapshot)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range healthHistoryLimit {
+			hc.store.UpdateHealth(link.ID, Health{Status: "unhealthy"}, start.Add(time.Duration(healthHistoryLimit+i)*time.Hour))
+		}
+	}()
+	for range healthHistoryLimit {
+		for _, record := range hc.store.GetLinks()[0].HealthHistory {
+			_ = record.Status
+		}
+	}
+	<-done
+
+	if !slices.Equal(snapshot, want) {
+		t.Fatalf("earlier GetLinks() history changed to %v, want %v", snapshot, want)
+	}
+}
+
+func TestHealthJobProgress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	hc := newTestHealthChecker(t)
+	for _, link := range []Link{
+		{URL: server.URL + "/ok", Path: []string{"Tech", "Go"}},
+		{URL: server.URL + "/missing", Path: []string{"Tech"}},
+		{URL: server.URL + "/other", Path: []string{"Reading"}},
+	} {
+		if _, err := hc.store.AddLink(link); err != nil {
+			t.Fatalf("AddLink() error = %v", err)
+		}
+	}
+	var links []Link
+	for _, link := range hc.store.GetLinks() {
+		if hasPathPrefix(link.Path, []string{"Tech"}) {
+			links = append(links, link)
+		}
+	}
+	job := hc.StartJob(links, []string{"Tech"}, nil)
+	deadline := time.Now().Add(5 * time.Second)
+	for job.Status != "completed" {
+		if time.Now().After(deadline) {
+			t.Fatalf("job did not complete: %#v", job)
+		}
+		time.Sleep(10 * time.Millisecond)
+		job, _ = hc.Job(job.ID)
+	}
+	if job.Total != 2 || job.Checked != 2 || job.Healthy != 1 || job.Unhealthy != 1 {
+		t.Fatalf("job = %#v, want 2 checked with 1 healthy", job)
+	}
+	for _, link := range hc.store.GetLinks() {
+		if link.Path[0] == "Reading" && !link.LastChecked.IsZero() {
+			t.Fatalf("link outside prefix was checked: %#v", link)
+		}
+	}
+}
+
+func TestCheckLinkRecordsRedirects(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/newer", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/newer", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/new", http.StatusPermanentRedirect)
+	})
+	mux.HandleFunc("/temporary", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/new", http.StatusFound)
+	})
+	mux.HandleFunc("/new", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/missing", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/nowhere", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMovedPermanently)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	hc := newTestHealthChecker(t)
+
+	got := hc.CheckLink(server.URL + "/old")
+	if got.Status != "healthy" || got.FinalURL != server.URL+"/new" || !got.MovedPermanently {
+		t.Fatalf("CheckLink(/old) = %#v, want permanent move to /new", got)
+	}
+	if len(got.Redirects) != 2 || got.Redirects[0].StatusCode != http.StatusMovedPermanently {
+		t.Fatalf("redirect chain = %#v, want two hops", got.Redirects)
+	}
+	got = hc.CheckLink(server.URL + "/temporary")
+	if got.FinalURL != server.URL+"/new" || got.MovedPermanently {
+		t.Fatalf("CheckLink(/temporary) = %#v, want temporary move", got)
+	}
+	got = hc.CheckLink(server.URL + "/gone")
+	if got.Status != "unhealthy" || got.FinalURL != "" || got.MovedPermanently || len(got.Redirects) != 1 {
+		t.Fatalf("CheckLink(/gone) = %#v, want the hop recorded but no final URL", got)
+	}
+	got = hc.CheckLink(server.URL + "/loop")
+	if got.Status != "unhealthy" || got.Error != errTooManyRedirects.Error() || got.FinalURL != "" || got.MovedPermanently || len(got.Redirects) != maxRedirects {
+		t.Fatalf("CheckLink(/loop) = %#v, want too many redirects and no final URL", got)
+	}
+	got = hc.CheckLink(server.URL + "/nowhere")
+	if got.Status != "unhealthy" || got.StatusCode != http.StatusMovedPermanently || got.FinalURL != "" || got.MovedPermanently {
+		t.Fatalf("CheckLink(/nowhere) = %#v, want a redirect without Location to be unhealthy", got)
+	}
+
+	moved, err := hc.store.AddLink(Link{URL: server.URL + "/old"})
+	if err != nil {
+		t.Fatalf("AddLink() error = %v", err)
+	}
+	if err := hc.store.UpdateHealth(moved.ID, hc.CheckLink(moved.URL), time.Now()); err != nil {
+		t.Fatalf("UpdateHealth() error = %v", err)
+	}
+	stale, err := hc.store.AddLink(Link{URL: server.URL + "/gone"})
+	if err != nil {
+		t.Fatalf("AddLink() error = %v", err)
+	}
+	if err := hc.store.UpdateHealth(stale.ID, Health{Status: "unhealthy", FinalURL: server.URL + "/missing"}, time.Now()); err != nil {
+		t.Fatalf("UpdateHealth() error = %v", err)
+	}
+	if _, err := hc.store.ApplyRedirect(stale.ID); !errors.Is(err, ErrRedirectUnhealthy) {
+		t.Fatalf("ApplyRedirect() to a broken target error = %v, want ErrRedirectUnhealthy", err)
+	}
+	// The target differs from the existing link only by a trailing slash.
+	duplicate, err := hc.store.AddLink(Link{URL: server.URL + "/new/"})
+	if err != nil {
+		t.Fatalf("AddLink() error = %v", err)
+	}
+	if _, err := hc.store.ApplyRedirect(moved.ID); !errors.Is(err, ErrLinkExists) {
+		t.Fatalf("ApplyRedirect() with existing target error = %v, want ErrLinkExists", err)
+	}
+	if err := hc.store.DeleteLink(duplicate.ID); err != nil {
+		t.Fatalf("DeleteLink() error = %v", err)
+	}
+	applied, err := hc.store.ApplyRedirect(moved.ID)
+	if err != nil {
+		t.Fatalf("ApplyRedirect() error = %v", err)
+	}
+	if applied.URL != server.URL+"/new" || applied.Health.MovedPermanently {
+		t.Fatalf("ApplyRedirect() = %#v, want rewritten URL", applied)
+	}
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const healthJobLimit = 50
+
+type HealthJob struct {
+	ID         string    `json:"id"`
+	Status     string    `json:"status"`
+	Path       []string  `json:"path,omitempty"`
+	LinkIDs    []string  `json:"linkIds,omitempty"`
+	Total      int       `json:"total"`
+	Checked    int       `json:"checked"`
+	Healthy    int       `json:"healthy"`
+	Unhealthy  int       `json:"unhealthy"`
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt,omitzero"`
+}
+
+type healthJobs struct {
+	jobs  map[string]*HealthJob
+	order []string
+	mu    sync.Mutex
+}
+
+func newHealthJobs() *healthJobs {
+	return &healthJobs{jobs: make(map[string]*HealthJob)}
+}
+
+func (j *healthJobs) start(total int, path, ids []string) string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	job := &HealthJob{
+		ID:        uuid.NewString(),
+		Status:    "running",
+		Path:      path,
+		LinkIDs:   ids,
+		Total:     total,
+		StartedAt: time.Now(),
+	}
+	j.jobs[job.ID] = job
+	j.order = append(j.order, job.ID)
+	for len(j.order) > healthJobLimit {
+		oldest := j.jobs[j.order[0]]
+		if oldest.Status == "running" {
+			break
+		}
+		delete(j.jobs, oldest.ID)
+		j.order = j.order[1:]
+	}
+	return job.ID
+}
+
+func (j *healthJobs) record(id string, health Health) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	job, ok := j.jobs[id]
+	if !ok {
+		return
+	}
+	job.Checked++
+	if health.Status == "healthy" {
+		job.Healthy++
+	} else {
+		job.Unhealthy++
+	}
+}
+
+func (j *healthJobs) finish(id string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if job, ok := j.jobs[id]; ok {
+		job.Status = "completed"
+		job.FinishedAt = time.Now()
+	}
+}
+
+func (j *healthJobs) get(id string) (HealthJob, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	job, ok := j.jobs[id]
+	if !ok {
+		return HealthJob{}, false
+	}
+	return *job, true
+}
+
+// StartJob checks the given links in the background and returns the job
+// that tracks their progress.
+func (hc *HealthChecker) StartJob(links []Link, path, ids []string) HealthJob {
+	id := hc.jobs.start(len(links), path, ids)
+	go hc.runJob(id, links)
+	job, _ := hc.jobs.get(id)
+	return job
+}
+
+func (hc *HealthChecker) runJob(id string, links []Link) {
+	slog.Debug("health check started", "job_id", id, "links", len(links))
+	hc.checkLinks(links, id)
+	hc.jobs.finish(id)
+	job, _ := hc.jobs.get(id)
+	scope := "all"
+	if job.Path != nil || job.LinkIDs != nil {
+		scope = "partial"
+	}
+	duration := job.FinishedAt.Sub(job.StartedAt)
+	metrics.observeHealthCheck(scope, duration, job.FinishedAt)
+	slog.Info("health check completed",
+		"job_id", job.ID,
+		"scope", scope,
+		"total", job.Total,
+		"healthy", job.Healthy,
+		"unhealthy", job.Unhealthy,
+		"duration", duration,
+	)
+	hc.store.Events().Publish(EventHealthCheckCompleted, HealthCheckEvent{Job: job})
+}
+
+func (hc *HealthChecker) Job(id string) (HealthJob, bool) {
+	return hc.jobs.get(id)
+}
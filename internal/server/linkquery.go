@@ -0,0 +1,320 @@
+package server
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxLinkPageSize = 1000
+
+var (
+	linkSortFields = []string{"created", "updated", "name", "url", "lastChecked", "health"}
+	linkFields     = []string{"id", "url", "name", "description", "path", "tags", "health", "lastChecked", "healthHistory", "createdAt", "updatedAt",
+		"readState", "progress", "archived", "starred", "queuedAt", "readAt"}
+)
+
+// LinkQuery selects, orders and pages links for GET /api/v1/links. The zero
+// value returns every link, oldest first. Ties are broken by ID rather than
+// store position, so a cursor stays valid while links are added or deleted.
+// Tag matches links carrying the tag, ignoring case.
+type LinkQuery struct {
+	Path   []string
+	Tag    string
+	Health string
+	Host   string
+	Since  time.Time
+	// ReadState matches the read-later state; "none" matches links that
+	// are not queued. Starred and Archived are ignored when nil.
+	ReadState string
+	Starred   *bool
+	Archived  *bool
+	Sort      string
+	Desc      bool
+	Limit     int
+	Cursor    string
+	Fields    []string
+}
+
+func parseLinkQuery(values url.Values) (LinkQuery, error) {
+	query := LinkQuery{
+		Path:   splitLinkPath(values.Get("path")),
+		Tag:    strings.TrimSpace(values.Get("tag")),
+		Health: values.Get("health"),
+		Host:   strings.ToLower(values.Get("host")),
+		Sort:   cmp.Or(values.Get("sort"), "created"),
+		Cursor: values.Get("cursor"),
+	}
+	if value := values.Get("since"); value != "" {
+		since, err := parseSince(value, time.Now())
+		if err != nil {
+			return LinkQuery{}, err
+		}
+		query.Since = since
+	}
+	if value := values.Get("readState"); value != "" {
+		if value != "none" && !slices.Contains(readStates, value) {
+			return LinkQuery{}, invalidRequest("readState must be one of none, " + strings.Join(readStates, ", "))
+		}
+		query.ReadState = value
+	}
+	for name, target := range map[string]**bool{"starred": &query.Starred, "archived": &query.Archived} {
+		if value := values.Get(name); value != "" {
+			flag, err := strconv.ParseBool(value)
+			if err != nil {
+				return LinkQuery{}, invalidRequest(name + " must be true or false")
+			}
+			*target = &flag
+		}
+	}
+	if !slices.Contains(linkSortFields, query.Sort) {
+		return LinkQuery{}, invalidRequest("sort must be one of " + strings.Join(linkSortFields, ", "))
+	}
+	switch values.Get("order") {
+	case "", "asc":
+	case "desc":
+		query.Desc = true
+	default:
+		return LinkQuery{}, invalidRequest("order must be asc or desc")
+	}
+	if value := values.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxLinkPageSize {
+			return LinkQuery{}, invalidRequest(fmt.Sprintf("limit must be between 1 and %d", maxLinkPageSize))
+		}
+		query.Limit = limit
+	}
+	if value := values.Get("fields"); value != "" {
+		for field := range strings.SplitSeq(value, ",") {
+			field = strings.TrimSpace(field)
+			if !slices.Contains(linkFields, field) {
+				return LinkQuery{}, invalidRequest(fmt.Sprintf("unknown field %q", field))
+			}
+			query.Fields = append(query.Fields, field)
+		}
+	}
+	return query, nil
+}
+
+// linkCursor marks the last link of a page. It records the sort it was
+// issued for so that it is not replayed against a different order.
+type linkCursor struct {
+	Sort string `json:"s"`
+	Desc bool   `json:"d,omitempty"`
+	Key  string `json:"k"`
+	ID   string `json:"i"`
+}
+
+func (q LinkQuery) encodeCursor(key, id string) string {
+	data, _ := json.Marshal(linkCursor{Sort: q.Sort, Desc: q.Desc, Key: key, ID: id})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func (q LinkQuery) decodeCursor() (linkCursor, error) {
+	var cursor linkCursor
+	data, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+	if err == nil {
+		err = json.Unmarshal(data, &cursor)
+	}
+	if err != nil || cursor.Sort != q.Sort || cursor.Desc != q.Desc {
+		return linkCursor{}, &APIError{Status: http.StatusBadRequest, Code: "invalid_cursor", Message: "cursor is invalid or was issued for a different sort"}
+	}
+	return cursor, nil
+}
+
+type sortedLink struct {
+	key  string
+	link Link
+}
+
+// Apply filters and sorts links and returns the requested page, the total
+// number of matching links and the cursor of the next page, if any.
+func (q LinkQuery) Apply(links []Link) ([]Link, int, string, error) {
+	matched := make([]sortedLink, 0, len(links))
+	for _, link := range links {
+		if !hasPathPrefix(link.Path, q.Path) || (q.Host != "" && linkHost(link.URL) != q.Host) {
+			continue
+		}
+		if q.Tag != "" && !hasTag(link.Tags, q.Tag) {
+			continue
+		}
+		if q.Health != "" && !matchesHealth(link.Health, q.Health) {
+			continue
+		}
+		if link.CreatedAt.Before(q.Since) || !q.matchesReadingState(link.ReadingState) {
+			continue
+		}
+		matched = append(matched, sortedLink{key: linkSortKey(link, q.Sort), link: link})
+	}
+	compare := func(a, b sortedLink) int {
+		result := cmp.Or(cmp.Compare(a.key, b.key), cmp.Compare(a.link.ID, b.link.ID))
+		if q.Desc {
+			return -result
+		}
+		return result
+	}
+	slices.SortStableFunc(matched, compare)
+	total := len(matched)
+
+	if q.Cursor != "" {
+		cursor, err := q.decodeCursor()
+		if err != nil {
+			return nil, 0, "", err
+		}
+		last := sortedLink{key: cursor.Key, link: Link{ID: cursor.ID}}
+		start, _ := slices.BinarySearchFunc(matched, last, compare)
+		for start < len(matched) && compare(matched[start], last) <= 0 {
+			start++
+		}
+		matched = matched[start:]
+	}
+	next := ""
+	if q.Limit > 0 && len(matched) > q.Limit {
+		matched = matched[:q.Limit]
+		last := matched[len(matched)-1]
+		next = q.encodeCursor(last.key, last.link.ID)
+	}
+	page := make([]Link, len(matched))
+	for i, item := range matched {
+		page[i] = item.link
+	}
+	return page, total, next, nil
+}
+
+// parseSince accepts an RFC 3339 timestamp or a duration before now, either
+// in Go syntax ("36h") or as a number of days ("7d").
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if since, err := time.Parse(time.RFC3339, value); err == nil {
+		return since, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	} else if duration, err := time.ParseDuration(value); err == nil && duration >= 0 {
+		return now.Add(-duration), nil
+	}
+	return time.Time{}, invalidRequest("since must be an RFC 3339 timestamp or a duration such as 7d or 12h")
+}
+
+func (q LinkQuery) matchesReadingState(state ReadingState) bool {
+	switch {
+	case q.ReadState == "none" && state.ReadState != "":
+		return false
+	case q.ReadState != "" && q.ReadState != "none" && state.ReadState != q.ReadState:
+		return false
+	case q.Starred != nil && state.Starred != *q.Starred:
+		return false
+	case q.Archived != nil && state.Archived != *q.Archived:
+		return false
+	}
+	return true
+}
+
+func hasTag(tags []string, tag string) bool {
+	return slices.ContainsFunc(tags, func(t string) bool { return strings.EqualFold(t, tag) })
+}
+
+func matchesHealth(health Health, status string) bool {
+	if status == "unknown" {
+		return health.Status == ""
+	}
+	return health.Status == status || health.State == status
+}
+
+// linkSortKey renders the sort field of a link as a string that orders the
+// same way as the field itself.
+func linkSortKey(link Link, field string) string {
+	switch field {
+	case "name":
+		return strings.ToLower(cmp.Or(link.Name, link.URL))
+	case "url":
+		return strings.ToLower(link.URL)
+	case "lastChecked":
+		if link.LastChecked.IsZero() {
+			return ""
+		}
+		return fmt.Sprintf("%020d", link.LastChecked.UnixNano())
+	case "updated":
+		return fmt.Sprintf("%020d", link.UpdatedAt.UnixNano())
+	case "health":
+		return strconv.Itoa(healthRank(link.Health))
+	}
+	return fmt.Sprintf("%020d", link.CreatedAt.UnixNano())
+}
+
+func healthRank(health Health) int {
+	switch {
+	case health.State == "dead":
+		return 2
+	case health.State == "degraded":
+		return 1
+	case health.Status == "healthy":
+		return 0
+	case health.Status == "":
+		return 3
+	}
+	return 2
+}
+
+// selectFields reduces each link to the requested JSON fields.
+func selectFields(links []Link, fields []string) ([]map[string]json.RawMessage, error) {
+	selected := make([]map[string]json.RawMessage, len(links))
+	for i, link := range links {
+		data, err := json.Marshal(link)
+		if err != nil {
+			return nil, err
+		}
+		var all map[string]json.RawMessage
+		if err := json.Unmarshal(data, &all); err != nil {
+			return nil, err
+		}
+		selected[i] = make(map[string]json.RawMessage, len(fields))
+		for _, field := range fields {
+			if value, ok := all[field]; ok {
+				selected[i][field] = value
+			}
+		}
+	}
+	return selected, nil
+}
+
+// writeJSONWithETag writes value with a strong ETag derived from the body and
+// answers 304 when the client already has that representation.
+func writeJSONWithETag(w http.ResponseWriter, r *http.Request, status int, value any) {
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(value); err != nil {
+		writeError(w, r, fmt.Errorf("encode response: %w", err))
+		return
+	}
+	sum := sha256.Sum256(body.Bytes())
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+	if matchesETag(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body.Bytes())
+}
+
+func matchesETag(header, etag string) bool {
+	for candidate := range strings.SplitSeq(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLinkQueryApply(t *testing.T) {
+	checked := time.Date(2026, time.July, 1, 0, 0, 0, 0, time.UTC)
+	links := []Link{
+		{ID: "c", URL: "https://b.example/go", Name: "Charlie", Path: []string{"Tech", "Go"}, Tags: []string{"Go", "reference"}, Health: Health{Status: "healthy"}, LastChecked: checked},
+		{ID: "a", URL: "https://a.example/", Name: "alpha", Path: []string{"Tech"}, Health: Health{Status: "unhealthy", State: "dead"}, LastChecked: checked.Add(time.Hour)},
+		{ID: "b", URL: "https://b.example/rust", Name: "Bravo", Path: []string{"Tech", "Rust"}, Tags: []string{"reference"}},
+		{ID: "d", URL: "https://d.example/", Name: "Delta", Path: []string{"News"}, Health: Health{Status: "unhealthy", State: "degraded"}, LastChecked: checked.Add(-time.Hour)},
+	}
+	tests := []struct {
+		name    string
+		query   string
+		wantIDs []string
+	}{
+		{name: "created ties by ID", query: "", wantIDs: []string{"a", "b", "c", "d"}},
+		{name: "name case-insensitive", query: "sort=name", wantIDs: []string{"a", "b", "c", "d"}},
+		{name: "last checked descending", query: "sort=lastChecked&order=desc", wantIDs: []string{"a", "c", "d", "b"}},
+		{name: "health rank", query: "sort=health", wantIDs: []string{"c", "d", "a", "b"}},
+		{name: "path prefix", query: "path=Tech&sort=url", wantIDs: []string{"a", "c", "b"}},
+		{name: "health state", query: "health=dead", wantIDs: []string{"a"}},
+		{name: "never checked", query: "health=unknown", wantIDs: []string{"b"}},
+		{name: "host", query: "host=B.example", wantIDs: []string{"b", "c"}},
+		{name: "tag case-insensitive", query: "tag=REFERENCE", wantIDs: []string{"b", "c"}},
+		{name: "tag and path", query: "tag=go&path=Tech", wantIDs: []string{"c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, _ := url.ParseQuery(tt.query)
+			query, err := parseLinkQuery(values)
+			if err != nil {
+				t.Fatalf("parseLinkQuery() error = %v", err)
+			}
+			page, total, next, err := query.Apply(links)
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if got := linkIDs(page); !slices.Equal(got, tt.wantIDs) || total != len(tt.wantIDs) || next != "" {
+				t.Fatalf("Apply() = %v (total %d, next %q), want %v", got, total, next, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestLinkQueryCursorPaging(t *testing.T) {
+	var links []Link
+	for _, id := range []string{"e", "b", "d", "a", "c"} {
+		links = append(links, Link{ID: id, URL: "https://" + id + ".example", Name: strings.ToUpper(id)})
+	}
+	query := LinkQuery{Sort: "name", Desc: true, Limit: 2}
+	var got []string
+	for range 5 {
+		page, total, next, err := query.Apply(links)
+		if err != nil {
+			t.Fatalf("Apply() error = %v", err)
+		}
+		if total != len(links) {
+			t.Fatalf("total = %d, want %d", total, len(links))
+		}
+		got = append(got, linkIDs(page)...)
+		if next == "" {
+			break
+		}
+		query.Cursor = next
+		links = append(links, Link{ID: "0" + next[:4], URL: "https://new.example/" + next, Name: "Z"})
+	}
+	if !slices.Equal(got, []string{"e", "d", "c", "b", "a"}) {
+		t.Fatalf("paged IDs = %v, want e d c b a", got)
+	}
+
+	query.Sort = "url"
+	if _, _, _, err := query.Apply(links); err == nil {
+		t.Fatal("Apply() with cursor from another sort error = nil")
+	}
+}
+
+func TestLinkQueryCursorSurvivesStoreChanges(t *testing.T) {
+	created := time.Date(2026, time.July, 1, 0, 0, 0, 0, time.UTC)
+	var links []Link
+	for _, id := range []string{"d", "a", "e", "c", "b"} {
+		links = append(links, Link{ID: id, URL: "https://" + id + ".example", CreatedAt: created})
+	}
+	query := LinkQuery{Sort: "created", Limit: 2}
+	page, _, next, err := query.Apply(links)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	got := linkIDs(page)
+	// A paged link is deleted and one is imported at the front of the
+	// store, shifting the positions of the rest.
+	links = slices.Insert(slices.Delete(links, 1, 2), 0, Link{ID: "0", URL: "https://0.example", CreatedAt: created})
+	query.Cursor = next
+	page, _, _, err = query.Apply(links)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	got = append(got, linkIDs(page)...)
+	if !slices.Equal(got, []string{"a", "b", "c", "d"}) {
+		t.Fatalf("paged IDs = %v, want a b then c d", got)
+	}
+}
+
+func TestListLinksETagAndFields(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	if _, err := store.AddLink(Link{URL: "https://one.example", Name: "One", Path: []string{"Tech"}}); err != nil {
+		t.Fatalf("AddLink() error = %v", err)
+	}
+	srv := New("", 0, store, NewHealthChecker(store, time.Hour), t.TempDir())
+	if err := srv.Setup(); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	get := func(target, etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		rec := httptest.NewRecorder()
+		srv.httpServer.Handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := get("/api/v1/links?fields=id,name", "")
+	if first.Code != http.StatusOK || first.Header().Get("ETag") == "" {
+		t.Fatalf("GET status = %d, ETag = %q", first.Code, first.Header().Get("ETag"))
+	}
+	if body := first.Body.String(); strings.Contains(body, "url") || !strings.Contains(body, `"name":"One"`) {
+		t.Fatalf("sparse body = %s, want only id and name", body)
+	}
+	if rec := get("/api/v1/links?fields=id,name", first.Header().Get("ETag")); rec.Code != http.StatusNotModified {
+		t.Fatalf("conditional GET status = %d, want 304", rec.Code)
+	}
+	if _, err := store.AddLink(Link{URL: "https://two.example", Name: "Two"}); err != nil {
+		t.Fatalf("AddLink() error = %v", err)
+	}
+	if rec := get("/api/v1/links?fields=id,name", first.Header().Get("ETag")); rec.Code != http.StatusOK {
+		t.Fatalf("GET after change status = %d, want 200", rec.Code)
+	}
+	if rec := get("/api/v1/links?fields=secret", ""); rec.Code != http.StatusBadRequest {
+		t.Fatalf("unknown field status = %d, want 400", rec.Code)
+	}
+}
+
+func linkIDs(links []Link) []string {
+	ids := make([]string, len(links))
+	for i, link := range links {
+		ids[i] = link.ID
+	}
+	return ids
+}
@@ -1,27 +1,153 @@
 package server
 
-import "time"
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
 
 type Link struct {
-	ID          string    `json:"id"`
-	URL         string    `json:"url"`
-	Name        string    `json:"name,omitempty"`
-	Description string    `json:"description,omitempty"`
-	Path        []string  `json:"path"`
-	Health      Health    `json:"health"`
-	LastChecked time.Time `json:"lastChecked"`
+	ID            string         `json:"id"`
+	URL           string         `json:"url"`
+	Name          string         `json:"name,omitempty"`
+	Description   string         `json:"description,omitempty"`
+	Path          []string       `json:"path"`
+	Tags          []string       `json:"tags,omitempty"`
+	Health        Health         `json:"health"`
+	LastChecked   time.Time      `json:"lastChecked"`
+	HealthHistory []HealthRecord `json:"healthHistory,omitempty"`
+	CreatedAt     time.Time      `json:"createdAt"`
+	UpdatedAt     time.Time      `json:"updatedAt"`
+	ReadingState
+}
+
+// ReadingState tracks a link in the read-later queue. It is changed only
+// through UpdateReadingState, so editing a link keeps it. A link with no read
+// state is not queued.
+type ReadingState struct {
+	ReadState string    `json:"readState,omitempty"`
+	Progress  int       `json:"progress,omitempty"`
+	Archived  bool      `json:"archived,omitempty"`
+	Starred   bool      `json:"starred,omitempty"`
+	QueuedAt  time.Time `json:"queuedAt,omitzero"`
+	ReadAt    time.Time `json:"readAt,omitzero"`
+}
+
+var readStates = []string{"unread", "reading", "read"}
+
+// ReadingStatePatch holds the reading state fields a request wants changed.
+type ReadingStatePatch struct {
+	ReadState *string `json:"readState"`
+	Progress  *int    `json:"progress"`
+	Archived  *bool   `json:"archived"`
+	Starred   *bool   `json:"starred"`
+}
+
+func (p ReadingStatePatch) validate() error {
+	if p.ReadState != nil && *p.ReadState != "" && !slices.Contains(readStates, *p.ReadState) {
+		return invalidRequest(`readState must be unread, reading, read or "" to leave the queue`)
+	}
+	if p.Progress != nil && (*p.Progress < 0 || *p.Progress > 100) {
+		return invalidRequest("progress must be between 0 and 100")
+	}
+	return nil
+}
+
+// apply changes the state as requested. Queueing a link stamps QueuedAt,
+// finishing it stamps ReadAt and sets progress to 100, and setting progress
+// on an unread link marks it as being read.
+func (p ReadingStatePatch) apply(state *ReadingState, now time.Time) {
+	if p.Archived != nil {
+		state.Archived = *p.Archived
+	}
+	if p.Starred != nil {
+		state.Starred = *p.Starred
+	}
+	if p.Progress != nil {
+		state.Progress = *p.Progress
+		if state.ReadState == "unread" && state.Progress > 0 {
+			state.ReadState = "reading"
+		}
+	}
+	if p.ReadState != nil && *p.ReadState != state.ReadState {
+		previous := state.ReadState
+		state.ReadState = *p.ReadState
+		switch {
+		case state.ReadState == "read":
+			state.ReadAt = now
+			state.Progress = 100
+		case state.ReadState == "":
+			state.QueuedAt, state.ReadAt, state.Progress = time.Time{}, time.Time{}, 0
+		case previous == "" || previous == "read":
+			state.QueuedAt, state.ReadAt = now, time.Time{}
+			if p.Progress == nil {
+				state.Progress = 0
+			}
+		}
+	}
 }
 
 type Health struct {
 	Status     string `json:"status"`
+	State      string `json:"state,omitempty"`
 	StatusCode int    `json:"statusCode,omitempty"`
+	Method     string `json:"method,omitempty"`
+	Attempts   int    `json:"attempts,omitempty"`
 	Error      string `json:"error,omitempty"`
+
+	FinalURL         string     `json:"finalUrl,omitempty"`
+	Redirects        []Redirect `json:"redirects,omitempty"`
+	MovedPermanently bool       `json:"movedPermanently,omitempty"`
 }
 
+type Redirect struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"statusCode"`
+}
+
+type HealthRecord struct {
+	Status     string    `json:"status"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	CheckedAt  time.Time `json:"checkedAt"`
+}
+
+// Category is a node of the tree built from link paths. Links and Health
+// count every link at or below it; the metadata comes from categories.json.
+// SortedCategories names the sub-categories by Order, then by name, since
+// Categories is keyed by name and has no order of its own.
 type Category struct {
-	Name       string               `json:"name"`
-	Path       []string             `json:"path"`
-	Categories map[string]*Category `json:"categories,omitempty"`
+	Name string   `json:"name"`
+	Path []string `json:"path"`
+	CategoryMeta
+	Links            int                  `json:"links"`
+	Health           CategoryHealth       `json:"health"`
+	Categories       map[string]*Category `json:"categories,omitempty"`
+	SortedCategories []string             `json:"sortedCategories,omitempty"`
+}
+
+// CategoryHealth counts links by derived health state. Links that have not
+// been checked often enough to have a state are unknown.
+type CategoryHealth struct {
+	Healthy  int `json:"healthy"`
+	Degraded int `json:"degraded"`
+	Dead     int `json:"dead"`
+	Unknown  int `json:"unknown"`
+}
+
+func (h *CategoryHealth) add(state string) {
+	switch state {
+	case "healthy":
+		h.Healthy++
+	case "degraded":
+		h.Degraded++
+	case "dead":
+		h.Dead++
+	default:
+		h.Unknown++
+	}
 }
 
 func NewCategory(name string, path []string) *Category {
@@ -31,3 +157,57 @@ func NewCategory(name string, path []string) *Category {
 		Categories: make(map[string]*Category),
 	}
 }
+
+// child returns the sub-category called name, adding it when missing.
+func (c *Category) child(name string) *Category {
+	if _, exists := c.Categories[name]; !exists {
+		c.Categories[name] = NewCategory(name, append(slices.Clone(c.Path), name))
+	}
+	return c.Categories[name]
+}
+
+// sortTree fills SortedCategories for c and all below it.
+func (c *Category) sortTree() {
+	c.SortedCategories = slices.SortedFunc(maps.Keys(c.Categories), func(a, b string) int {
+		return cmp.Or(cmp.Compare(c.Categories[a].Order, c.Categories[b].Order), strings.Compare(a, b))
+	})
+	for _, child := range c.Categories {
+		child.sortTree()
+	}
+}
+
+func hasPathPrefix(path, prefix []string) bool {
+	return len(path) >= len(prefix) && slices.Equal(path[:len(prefix)], prefix)
+}
+
+// clone copies a link together with its slices, so the copy can be handed
+// out while the store keeps changing the original.
+func (l Link) clone() Link {
+	l.Path = slices.Clone(l.Path)
+	l.Tags = slices.Clone(l.Tags)
+	l.HealthHistory = slices.Clone(l.HealthHistory)
+	return l
+}
+
+// normalizeTags trims tags and drops empty ones and repeats. Tags compare
+// without case, and the first spelling of a tag is kept.
+func normalizeTags(tags []string) []string {
+	var normalized []string
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !slices.ContainsFunc(normalized, func(seen string) bool { return strings.EqualFold(seen, tag) }) {
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized
+}
+
+func splitLinkPath(value string) []string {
+	var path []string
+	for segment := range strings.SplitSeq(value, "/") {
+		if segment = strings.TrimSpace(segment); segment != "" {
+			path = append(path, segment)
+		}
+	}
+	return path
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestLoggingPropagatesID(t *testing.T) {
+	var output bytes.Buffer
+	logger, err := NewLogger(&output, "debug", "json")
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+	previous := slog.Default()
+	slog.SetDefault(logger)
+	defer slog.SetDefault(previous)
+
+	handler := logRequests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		slog.ErrorContext(r.Context(), "failed to do work")
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}))
+	req := httptest.NewRequest(http.MethodPost, "/api/links", nil)
+	req.Header.Set(requestIDHeader, "proxy-id")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get(requestIDHeader); got != "proxy-id" {
+		t.Fatalf("%s = %q, want proxy-id", requestIDHeader, got)
+	}
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("log records = %d, want 2:\n%s", len(lines), output.String())
+	}
+	for _, line := range lines {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+		if record["request_id"] != "proxy-id" {
+			t.Fatalf("record = %v, want request_id proxy-id", record)
+		}
+	}
+	var request map[string]any
+	if err := json.Unmarshal([]byte(lines[1]), &request); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if request["msg"] != "request" || request["status"] != float64(500) || request["level"] != "ERROR" || request["path"] != "/api/links" {
+		t.Fatalf("request record = %v", request)
+	}
+
+	if _, err := NewLogger(&output, "info", "xml"); err == nil {
+		t.Fatal("NewLogger() with unknown format error = nil")
+	}
+}
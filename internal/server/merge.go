@@ -0,0 +1,229 @@
+package server
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// MergeRequest folds the items in IDs into the one with ID Keep, which
+// survives. A merged bookmark stays in the folder of the kept one.
+type MergeRequest struct {
+	Keep string   `json:"keep"`
+	IDs  []string `json:"ids"`
+}
+
+func (m MergeRequest) validate() error {
+	if m.Keep == "" {
+		return invalidRequest("keep is required")
+	}
+	if !slices.ContainsFunc(m.IDs, func(id string) bool { return id != m.Keep }) {
+		return invalidRequest("ids must name at least one item besides keep")
+	}
+	return nil
+}
+
+// others returns the IDs to fold into Keep, without Keep and repeats.
+func (m MergeRequest) others() []string {
+	var ids []string
+	for _, id := range m.IDs {
+		if id != m.Keep && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// mergeLink folds other into kept. Empty fields of kept are filled from
+// other, distinct descriptions and tags are joined, and the earliest creation
+// time and any star survive. A link left in Uncategorized takes the other
+// link's path.
+func mergeLink(kept *Link, other Link) {
+	kept.Name = cmp.Or(kept.Name, other.Name)
+	if other.Description != "" && !strings.Contains(kept.Description, other.Description) {
+		kept.Description = strings.TrimSpace(kept.Description + "\n\n" + other.Description)
+	}
+	kept.Tags = normalizeTags(slices.Concat(kept.Tags, other.Tags))
+	if len(other.Path) > 0 && (len(kept.Path) == 0 || slices.Equal(kept.Path, []string{"Uncategorized"})) {
+		kept.Path = other.Path
+	}
+	if !other.CreatedAt.IsZero() && other.CreatedAt.Before(kept.CreatedAt) {
+		kept.CreatedAt = other.CreatedAt
+	}
+	starred := kept.Starred || other.Starred
+	if kept.ReadState == "" {
+		kept.ReadingState = other.ReadingState
+	}
+	kept.Starred = starred
+}
+
+// mergeBookmark folds other into kept, filling empty fields and keeping the
+// earliest creation time. The kept bookmark stays in its folder.
+func mergeBookmark(kept *BookmarkLink, other BookmarkLink) {
+	kept.Name = cmp.Or(kept.Name, other.Name)
+	kept.Icon = cmp.Or(kept.Icon, other.Icon)
+	kept.Color = cmp.Or(kept.Color, other.Color)
+	if !other.CreatedAt.IsZero() && other.CreatedAt.Before(kept.CreatedAt) {
+		kept.CreatedAt = other.CreatedAt
+	}
+}
+
+// MergeLinks folds the other links of the request into the kept one and
+// deletes them, in one write.
+func (s *JSONStore) MergeLinks(request MergeRequest) (Link, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	byID := func(id string) func(Link) bool {
+		return func(link Link) bool { return link.ID == id }
+	}
+	keptIndex := slices.IndexFunc(s.links, byID(request.Keep))
+	if keptIndex == -1 {
+		return Link{}, fmt.Errorf("link %s: %w", request.Keep, ErrLinkNotFound)
+	}
+	kept := s.links[keptIndex]
+	var removed []Link
+	for _, id := range request.others() {
+		index := slices.IndexFunc(s.links, byID(id))
+		if index == -1 {
+			return Link{}, fmt.Errorf("link %s: %w", id, ErrLinkNotFound)
+		}
+		mergeLink(&kept, s.links[index])
+		removed = append(removed, s.links[index])
+	}
+	kept.UpdatedAt = time.Now().UTC()
+	links := slices.DeleteFunc(slices.Clone(s.links), func(link Link) bool {
+		return slices.ContainsFunc(removed, func(other Link) bool { return other.ID == link.ID })
+	})
+	links[slices.IndexFunc(links, byID(kept.ID))] = kept
+	s.links = links
+	if err := s.saveToFile(); err != nil {
+		return Link{}, err
+	}
+	for _, link := range removed {
+		s.events.Publish(EventLinkDeleted, LinkEvent{Link: link.clone()})
+	}
+	s.events.Publish(EventLinkUpdated, LinkEvent{Link: kept.clone()})
+	return kept, nil
+}
+
+// Merge folds the other bookmarks of the request into the kept one and
+// deletes them, in one write.
+func (s *BookmarkStore) Merge(request MergeRequest) (BookmarkLink, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	config, _, err := s.loadNormalized()
+	if err != nil {
+		return BookmarkLink{}, err
+	}
+	links, index, folder := findBookmark(&config, request.Keep)
+	if links == nil {
+		return BookmarkLink{}, fmt.Errorf("bookmark %s: %w", request.Keep, ErrBookmarkNotFound)
+	}
+	kept := (*links)[index]
+	var removed []string
+	for _, id := range request.others() {
+		other, ok := removeBookmarkID(&config, id)
+		if !ok {
+			return BookmarkLink{}, fmt.Errorf("bookmark %s: %w", id, ErrBookmarkNotFound)
+		}
+		mergeBookmark(&kept, other)
+		removed = append(removed, id)
+	}
+	kept.UpdatedAt = time.Now().UTC()
+	// Removing the others may have shifted the kept bookmark.
+	links, index, _ = findBookmark(&config, kept.ID)
+	(*links)[index] = kept
+	pruneBookmarks(&config)
+	if err := s.save(config); err != nil {
+		return BookmarkLink{}, err
+	}
+	for _, id := range removed {
+		s.events.Publish(EventBookmarkDeleted, BookmarkEvent{Bookmark: BookmarkLink{ID: id}})
+	}
+	s.events.Publish(EventBookmarkUpdated, BookmarkEvent{Bookmark: kept, Folder: strings.Join(folder, "/")})
+	return kept, nil
+}
+
+// BookmarkDuplicate is a bookmark with the folder it is filed under.
+type BookmarkDuplicate struct {
+	BookmarkLink
+	Folder string `json:"folder"`
+}
+
+// BookmarkDuplicateGroup holds bookmarks whose URLs match after
+// normalisation, ignoring the scheme and a leading www.
+type BookmarkDuplicateGroup struct {
+	Key       string              `json:"key"`
+	Bookmarks []BookmarkDuplicate `json:"bookmarks"`
+}
+
+func findBookmarkDuplicates(config BookmarkConfig, normalizer *URLNormalizer) []BookmarkDuplicateGroup {
+	groups := map[string][]BookmarkDuplicate{}
+	forEachBookmark(&config, func(path []string, link *BookmarkLink) {
+		key := normalizer.nearKey(link.URL)
+		groups[key] = append(groups[key], BookmarkDuplicate{BookmarkLink: *link, Folder: strings.Join(path, "/")})
+	})
+	duplicates := []BookmarkDuplicateGroup{}
+	for key, group := range groups {
+		if len(group) > 1 {
+			duplicates = append(duplicates, BookmarkDuplicateGroup{Key: key, Bookmarks: group})
+		}
+	}
+	slices.SortFunc(duplicates, func(a, b BookmarkDuplicateGroup) int {
+		return cmp.Compare(a.Key, b.Key)
+	})
+	return duplicates
+}
+
+func (s *Server) handleBookmarkDuplicates(w http.ResponseWriter, r *http.Request) {
+	config, err := s.bookmarks.Load()
+	if err != nil {
+		writeError(w, r, fmt.Errorf("load bookmarks: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, findBookmarkDuplicates(config, s.normalizer))
+}
+
+func decodeMergeRequest(w http.ResponseWriter, r *http.Request) (MergeRequest, bool) {
+	var request MergeRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, r, ErrInvalidJSON)
+		return MergeRequest{}, false
+	}
+	if err := request.validate(); err != nil {
+		writeError(w, r, err)
+		return MergeRequest{}, false
+	}
+	return request, true
+}
+
+func (s *Server) handleLinksMerge(w http.ResponseWriter, r *http.Request) {
+	request, ok := decodeMergeRequest(w, r)
+	if !ok {
+		return
+	}
+	link, err := s.store.MergeLinks(request)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("merge links into %s: %w", request.Keep, err))
+		return
+	}
+	writeJSON(w, http.StatusOK, link)
+}
+
+func (s *Server) handleBookmarksMerge(w http.ResponseWriter, r *http.Request) {
+	request, ok := decodeMergeRequest(w, r)
+	if !ok {
+		return
+	}
+	bookmark, err := s.bookmarks.Merge(request)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("merge bookmarks into %s: %w", request.Keep, err))
+		return
+	}
+	writeJSON(w, http.StatusOK, bookmark)
+}
@@ -0,0 +1,170 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMergeLinks(t *testing.T) {
+	tests := []struct {
+		name            string
+		request         func(ids []string) MergeRequest
+		wantErr         error
+		wantLinks       int
+		wantName        string
+		wantDescription string
+		wantPath        string
+		wantTags        []string
+		wantStarred     bool
+	}{
+		{
+			name: "combines fields",
+			request: func(ids []string) MergeRequest {
+				return MergeRequest{Keep: ids[0], IDs: []string{ids[1], ids[2], ids[1]}}
+			},
+			wantLinks:       1,
+			wantName:        "Docs",
+			wantDescription: "First\n\nSecond",
+			wantPath:        "Work/Docs",
+			wantTags:        []string{"go", "Docs", "reference"},
+			wantStarred:     true,
+		},
+		{
+			name:            "keeps the kept path",
+			request:         func(ids []string) MergeRequest { return MergeRequest{Keep: ids[1], IDs: []string{ids[0]}} },
+			wantLinks:       2,
+			wantName:        "Docs",
+			wantDescription: "Second\n\nFirst",
+			wantPath:        "Work/Docs",
+			wantTags:        []string{"docs", "reference", "go"},
+			wantStarred:     true,
+		},
+		{
+			name:      "missing ID changes nothing",
+			request:   func(ids []string) MergeRequest { return MergeRequest{Keep: ids[0], IDs: []string{ids[1], "missing"}} },
+			wantErr:   ErrLinkNotFound,
+			wantLinks: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			store, err := NewStore(dir)
+			if err != nil {
+				t.Fatalf("NewStore() error = %v", err)
+			}
+			var ids []string
+			for _, seed := range []Link{
+				{URL: "https://example.com/docs", Description: "First", Tags: []string{"go", "Docs"}},
+				{URL: "http://www.example.com/docs", Name: "Docs", Description: "Second", Path: []string{"Work", "Docs"}, Tags: []string{"docs", "reference"}},
+				{URL: "https://example.com/docs?page=2", Description: "First", Tags: []string{"Go"}},
+			} {
+				link, err := store.AddLink(seed)
+				if err != nil {
+					t.Fatalf("AddLink() error = %v", err)
+				}
+				ids = append(ids, link.ID)
+			}
+			starred := true
+			if _, err := store.UpdateReadingState(ids[2], ReadingStatePatch{Starred: &starred}); err != nil {
+				t.Fatalf("UpdateReadingState() error = %v", err)
+			}
+			if _, err := store.UpdateReadingState(ids[1], ReadingStatePatch{Starred: &starred}); err != nil {
+				t.Fatalf("UpdateReadingState() error = %v", err)
+			}
+
+			merged, err := store.MergeLinks(tt.request(ids))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("MergeLinks() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := len(store.GetLinks()); got != tt.wantLinks {
+				t.Fatalf("links after merge = %d, want %d", got, tt.wantLinks)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if merged.Name != tt.wantName || merged.Description != tt.wantDescription || strings.Join(merged.Path, "/") != tt.wantPath ||
+				!slices.Equal(merged.Tags, tt.wantTags) || merged.Starred != tt.wantStarred {
+				t.Fatalf("MergeLinks() = %+v", merged)
+			}
+			reloaded, err := NewStore(dir)
+			if err != nil {
+				t.Fatalf("NewStore() error = %v", err)
+			}
+			if !slices.ContainsFunc(reloaded.GetLinks(), func(link Link) bool { return link.ID == merged.ID && link.Description == tt.wantDescription }) {
+				t.Fatalf("merged link was not saved")
+			}
+		})
+	}
+}
+
+func TestBookmarkMerge(t *testing.T) {
+	store := NewBookmarkStore(t.TempDir())
+	kept, err := store.Create("Work", BookmarkLink{Name: "Docs", URL: "https://docs.example"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	other, err := store.Create("Home/Reading", BookmarkLink{Name: "Docs again", URL: "http://www.docs.example/", Icon: "book"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	config, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	groups := findBookmarkDuplicates(config, NewURLNormalizer(URLNormalization{}))
+	if len(groups) != 1 || len(groups[0].Bookmarks) != 2 || groups[0].Bookmarks[1].Folder != "Home/Reading" {
+		t.Fatalf("findBookmarkDuplicates() = %+v, want one group of two", groups)
+	}
+
+	if _, err := store.Merge(MergeRequest{Keep: kept.ID, IDs: []string{"missing"}}); !errors.Is(err, ErrBookmarkNotFound) {
+		t.Fatalf("Merge() missing error = %v, want %v", err, ErrBookmarkNotFound)
+	}
+	merged, err := store.Merge(MergeRequest{Keep: kept.ID, IDs: []string{other.ID}})
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	if merged.Name != "Docs" || merged.Icon != "book" {
+		t.Fatalf("Merge() = %+v, want the kept name and the other icon", merged)
+	}
+	config, err = store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(config.Bookmarks) != 1 || config.Bookmarks[0].Category != "Work" || len(config.Bookmarks[0].Links) != 1 {
+		t.Fatalf("bookmarks after merge = %+v, want only Work with the kept bookmark", config.Bookmarks)
+	}
+}
+
+func TestMergeRoutes(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	srv := New("", 0, store, NewHealthChecker(store, time.Hour), t.TempDir())
+	if err := srv.Setup(); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	for _, tt := range []struct {
+		target string
+		body   string
+		want   int
+	}{
+		{"/api/v1/links/merge", `{"keep":"a","ids":["a"]}`, http.StatusBadRequest},
+		{"/api/v1/links/merge", `{"ids":["b"]}`, http.StatusBadRequest},
+		{"/api/v1/links/merge", `{"keep":"a","ids":["b"]}`, http.StatusNotFound},
+		{"/api/v1/bookmarks/merge", `{"keep":"a"`, http.StatusBadRequest},
+		{"/api/v1/bookmarks/merge", `{"keep":"a","ids":["b"]}`, http.StatusNotFound},
+	} {
+		rec := httptest.NewRecorder()
+		srv.httpServer.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body)))
+		if rec.Code != tt.want {
+			t.Fatalf("POST %s %s status = %d, want %d", tt.target, tt.body, rec.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const maxMetadataBytes = 512 << 10
+
+// PageMetadata is what a page says about itself in its head.
+type PageMetadata struct {
+	Title       string
+	Description string
+}
+
+// MetadataFetcher looks up the title and description of a page so that
+// saved links get a name without the user typing one.
+type MetadataFetcher interface {
+	Fetch(ctx context.Context, rawURL string) (PageMetadata, error)
+}
+
+type HTMLMetadataFetcher struct {
+	client *http.Client
+}
+
+func NewHTMLMetadataFetcher() *HTMLMetadataFetcher {
+	return &HTMLMetadataFetcher{client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+func (f *HTMLMetadataFetcher) Fetch(ctx context.Context, rawURL string) (PageMetadata, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return PageMetadata{}, err
+	}
+	req.Header.Set("User-Agent", "LinkSnapper")
+	req.Header.Set("Accept", "text/html")
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return PageMetadata{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return PageMetadata{}, fmt.Errorf("page returned %d", resp.StatusCode)
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" && !strings.Contains(contentType, "html") {
+		return PageMetadata{}, fmt.Errorf("page is %s, not HTML", contentType)
+	}
+	page, err := io.ReadAll(io.LimitReader(resp.Body, maxMetadataBytes))
+	if err != nil {
+		return PageMetadata{}, err
+	}
+	return parsePageMetadata(string(page)), nil
+}
+
+var (
+	titlePattern     = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	metaPattern      = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	attributePattern = regexp.MustCompile(`(?is)([a-z:-]+)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+)
+
+// parsePageMetadata reads the title and description from a page, preferring
+// Open Graph tags over <title> and the plain description meta tag.
+func parsePageMetadata(page string) PageMetadata {
+	meta := map[string]string{}
+	for _, tag := range metaPattern.FindAllString(page, -1) {
+		attributes := map[string]string{}
+		for _, match := range attributePattern.FindAllStringSubmatch(tag, -1) {
+			attributes[strings.ToLower(match[1])] = match[2] + match[3]
+		}
+		key := strings.ToLower(cmp.Or(attributes["property"], attributes["name"]))
+		if _, seen := meta[key]; key != "" && !seen {
+			meta[key] = attributes["content"]
+		}
+	}
+	var title string
+	if match := titlePattern.FindStringSubmatch(page); match != nil {
+		title = match[1]
+	}
+	return PageMetadata{
+		Title:       cleanMetadata(cmp.Or(meta["og:title"], title)),
+		Description: cleanMetadata(cmp.Or(meta["og:description"], meta["description"])),
+	}
+}
+
+func cleanMetadata(value string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(value)), " ")
+}
@@ -0,0 +1,268 @@
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	requestDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+	storeWriteBuckets      = []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1}
+	healthCheckBuckets     = []float64{1, 5, 10, 30, 60, 120, 300, 600, 1800}
+)
+
+// metrics is the process-wide registry rendered by /metrics. Link, bookmark
+// and health totals are read from the stores at scrape time; everything else
+// is recorded as it happens.
+var metrics = newMetricsRegistry()
+
+type histogram struct {
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+func (h *histogram) observe(value float64) {
+	for i, bound := range h.buckets {
+		if value <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += value
+	h.count++
+}
+
+func (h *histogram) clone() *histogram {
+	clone := *h
+	clone.counts = slices.Clone(h.counts)
+	return &clone
+}
+
+type requestKey struct {
+	route  string
+	method string
+	code   string
+}
+
+type routeKey struct {
+	route  string
+	method string
+}
+
+type metricsRegistry struct {
+	mu                  sync.Mutex
+	requests            map[requestKey]uint64
+	requestDurations    map[routeKey]*histogram
+	storeWrites         map[string]*histogram
+	storeWriteFailures  map[string]uint64
+	healthChecks        map[string]*histogram
+	healthCheckLastDone map[string]time.Time
+}
+
+func newMetricsRegistry() *metricsRegistry {
+	return &metricsRegistry{
+		requests:            make(map[requestKey]uint64),
+		requestDurations:    make(map[routeKey]*histogram),
+		storeWrites:         make(map[string]*histogram),
+		storeWriteFailures:  make(map[string]uint64),
+		healthChecks:        make(map[string]*histogram),
+		healthCheckLastDone: make(map[string]time.Time),
+	}
+}
+
+func (m *metricsRegistry) observeRequest(route, method string, code int, elapsed time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[requestKey{route: route, method: method, code: strconv.Itoa(code)}]++
+	key := routeKey{route: route, method: method}
+	if m.requestDurations[key] == nil {
+		m.requestDurations[key] = newHistogram(requestDurationBuckets)
+	}
+	m.requestDurations[key].observe(elapsed.Seconds())
+}
+
+func (m *metricsRegistry) observeStoreWrite(store string, elapsed time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.storeWrites[store] == nil {
+		m.storeWrites[store] = newHistogram(storeWriteBuckets)
+	}
+	m.storeWrites[store].observe(elapsed.Seconds())
+	if err != nil {
+		m.storeWriteFailures[store]++
+	}
+}
+
+func (m *metricsRegistry) observeHealthCheck(scope string, elapsed time.Duration, finished time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.healthChecks[scope] == nil {
+		m.healthChecks[scope] = newHistogram(healthCheckBuckets)
+	}
+	m.healthChecks[scope].observe(elapsed.Seconds())
+	m.healthCheckLastDone[scope] = finished
+}
+
+func cloneHistograms[K comparable](histograms map[K]*histogram) map[K]*histogram {
+	clone := make(map[K]*histogram, len(histograms))
+	for key, h := range histograms {
+		clone[key] = h.clone()
+	}
+	return clone
+}
+
+// snapshot returns a deep copy of the registry, so that a scrape can render
+// it without holding the lock while writing to a slow client.
+func (m *metricsRegistry) snapshot() *metricsRegistry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return &metricsRegistry{
+		requests:            maps.Clone(m.requests),
+		requestDurations:    cloneHistograms(m.requestDurations),
+		storeWrites:         cloneHistograms(m.storeWrites),
+		storeWriteFailures:  maps.Clone(m.storeWriteFailures),
+		healthChecks:        cloneHistograms(m.healthChecks),
+		healthCheckLastDone: maps.Clone(m.healthCheckLastDone),
+	}
+}
+
+// instrument records the count and latency of every request, labelled with
+// the mux pattern that served it so that link IDs do not become labels.
+func instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		route := r.Pattern
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.observeRequest(route, r.Method, recorder.status, time.Since(started))
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(data []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	out := bufio.NewWriter(w)
+	defer out.Flush()
+
+	links := s.store.GetLinks()
+	writeMetricHeader(out, "linksnapper_links", "gauge", "Number of saved resource links.")
+	fmt.Fprintf(out, "linksnapper_links %d\n", len(links))
+
+	bookmarkCount := 0
+	if config, err := s.bookmarks.Load(); err == nil {
+		bookmarkCount = len(bookmarkLinks(config))
+	}
+	writeMetricHeader(out, "linksnapper_bookmarks", "gauge", "Number of saved bookmarks.")
+	fmt.Fprintf(out, "linksnapper_bookmarks %d\n", bookmarkCount)
+
+	health := make(map[[2]string]int)
+	for _, link := range links {
+		status := link.Health.Status
+		if status == "" {
+			status = "unknown"
+		}
+		health[[2]string{status, statusClass(link.Health.StatusCode)}]++
+	}
+	writeMetricHeader(out, "linksnapper_links_health", "gauge", "Number of links by last health status and HTTP status code class.")
+	for _, key := range slices.SortedFunc(maps.Keys(health), func(a, b [2]string) int {
+		return strings.Compare(a[0]+" "+a[1], b[0]+" "+b[1])
+	}) {
+		fmt.Fprintf(out, "linksnapper_links_health{status=%q,code_class=%q} %d\n", key[0], key[1], health[key])
+	}
+
+	recorded := metrics.snapshot()
+
+	writeMetricHeader(out, "linksnapper_http_requests_total", "counter", "HTTP requests by route, method and status code.")
+	for _, key := range slices.SortedFunc(maps.Keys(recorded.requests), func(a, b requestKey) int {
+		return strings.Compare(a.route+" "+a.method+" "+a.code, b.route+" "+b.method+" "+b.code)
+	}) {
+		fmt.Fprintf(out, "linksnapper_http_requests_total{route=%q,method=%q,code=%q} %d\n", key.route, key.method, key.code, recorded.requests[key])
+	}
+	writeMetricHeader(out, "linksnapper_http_request_duration_seconds", "histogram", "HTTP request latency by route and method.")
+	for _, key := range slices.SortedFunc(maps.Keys(recorded.requestDurations), func(a, b routeKey) int {
+		return strings.Compare(a.route+" "+a.method, b.route+" "+b.method)
+	}) {
+		writeHistogram(out, "linksnapper_http_request_duration_seconds", fmt.Sprintf("route=%q,method=%q", key.route, key.method), recorded.requestDurations[key])
+	}
+
+	writeMetricHeader(out, "linksnapper_health_check_duration_seconds", "histogram", "Duration of health check passes by scope.")
+	for _, scope := range slices.Sorted(maps.Keys(recorded.healthChecks)) {
+		writeHistogram(out, "linksnapper_health_check_duration_seconds", fmt.Sprintf("scope=%q", scope), recorded.healthChecks[scope])
+	}
+	writeMetricHeader(out, "linksnapper_health_check_last_completion_timestamp_seconds", "gauge", "Unix time of the last completed health check pass by scope.")
+	for _, scope := range slices.Sorted(maps.Keys(recorded.healthCheckLastDone)) {
+		fmt.Fprintf(out, "linksnapper_health_check_last_completion_timestamp_seconds{scope=%q} %d\n", scope, recorded.healthCheckLastDone[scope].Unix())
+	}
+
+	writeMetricHeader(out, "linksnapper_store_write_duration_seconds", "histogram", "Latency of writes to the data files by store.")
+	for _, store := range slices.Sorted(maps.Keys(recorded.storeWrites)) {
+		writeHistogram(out, "linksnapper_store_write_duration_seconds", fmt.Sprintf("store=%q", store), recorded.storeWrites[store])
+	}
+	writeMetricHeader(out, "linksnapper_store_write_failures_total", "counter", "Failed writes to the data files by store.")
+	for _, store := range slices.Sorted(maps.Keys(recorded.storeWrites)) {
+		fmt.Fprintf(out, "linksnapper_store_write_failures_total{store=%q} %d\n", store, recorded.storeWriteFailures[store])
+	}
+}
+
+func writeMetricHeader(out *bufio.Writer, name, kind, help string) {
+	fmt.Fprintf(out, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func writeHistogram(out *bufio.Writer, name, labels string, h *histogram) {
+	for i, bound := range h.buckets {
+		fmt.Fprintf(out, "%s_bucket{%s,le=%q} %d\n", name, labels, strconv.FormatFloat(bound, 'g', -1, 64), h.counts[i])
+	}
+	fmt.Fprintf(out, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels, h.count)
+	fmt.Fprintf(out, "%s_sum{%s} %s\n", name, labels, strconv.FormatFloat(h.sum, 'g', -1, 64))
+	fmt.Fprintf(out, "%s_count{%s} %d\n", name, labels, h.count)
+}
+
+func statusClass(code int) string {
+	if code < 100 || code > 599 {
+		return "none"
+	}
+	return strconv.Itoa(code/100) + "xx"
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetricsExposition(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	srv := New("", 0, store, NewHealthChecker(store, time.Hour), t.TempDir())
+	if err := srv.Setup(); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	httpServer := httptest.NewServer(srv.httpServer.Handler)
+	defer httpServer.Close()
+
+	if _, err := store.AddLink(Link{URL: "https://ok.example", Health: Health{Status: "healthy", StatusCode: 200}}); err != nil {
+		t.Fatalf("AddLink() error = %v", err)
+	}
+	if _, err := store.AddLink(Link{URL: "https://gone.example", Health: Health{Status: "unhealthy", StatusCode: 404}}); err != nil {
+		t.Fatalf("AddLink() error = %v", err)
+	}
+	if _, err := srv.bookmarks.Create("Work", BookmarkLink{Name: "Docs", URL: "https://docs.example"}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	resp, err := http.Get(httpServer.URL + "/api/links/missing/health")
+	if err != nil {
+		t.Fatalf("GET health error = %v", err)
+	}
+	resp.Body.Close()
+
+	resp, err = http.Get(httpServer.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics error = %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	for _, want := range []string{
+		"linksnapper_links 2\n",
+		"linksnapper_bookmarks 1\n",
+		`linksnapper_links_health{status="healthy",code_class="2xx"} 1`,
+		`linksnapper_links_health{status="unhealthy",code_class="4xx"} 1`,
+		`linksnapper_http_requests_total{route="GET /api/links/{id}/health",method="GET",code="404"}`,
+		`linksnapper_http_request_duration_seconds_bucket{route="GET /api/links/{id}/health",method="GET",le="+Inf"}`,
+		`linksnapper_store_write_duration_seconds_count{store="links"}`,
+		`linksnapper_store_write_failures_total{store="bookmarks"} 0`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Fatalf("metrics output missing %q:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package server
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	notifierWorkers    = 4
+	notifierQueueLimit = 1024
+)
+
+type Notifier struct {
+	bus          *EventBus
+	webhooks     []WebhookConfig
+	client       *http.Client
+	deadLetter   string
+	retries      int
+	backoff      time.Duration
+	subscription *Subscription
+	queue        chan delivery
+	wg           sync.WaitGroup
+	mu           sync.Mutex
+}
+
+// delivery is one event queued for one webhook.
+type delivery struct {
+	hook  WebhookConfig
+	event Event
+}
+
+// deadLetter records an event that could not be delivered. Events dropped
+// because the notifier fell behind the bus are recorded by ID range instead,
+// since their contents are gone.
+type deadLetter struct {
+	Webhook    string    `json:"webhook,omitempty"`
+	URL        string    `json:"url,omitempty"`
+	Event      *Event    `json:"event,omitempty"`
+	MissedFrom uint64    `json:"missedFrom,omitempty"`
+	MissedTo   uint64    `json:"missedTo,omitempty"`
+	Error      string    `json:"error"`
+	FailedAt   time.Time `json:"failedAt"`
+}
+
+func NewNotifier(bus *EventBus, webhooks []WebhookConfig, deadLetterFile string) *Notifier {
+	return &Notifier{
+		bus:        bus,
+		webhooks:   webhooks,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		deadLetter: deadLetterFile,
+		retries:    3,
+		backoff:    2 * time.Second,
+	}
+}
+
+// Start subscribes to the bus and delivers events through a fixed pool of
+// workers. The dispatcher hands events to the workers through a queue of its
+// own; when that queue stays full the bus subscription overflows, and the
+// events it loses are written to the dead-letter file as a missed range.
+func (n *Notifier) Start() {
+	if len(n.webhooks) == 0 {
+		return
+	}
+	n.subscription = n.bus.Subscribe(256)
+	n.queue = make(chan delivery, notifierQueueLimit)
+	for range notifierWorkers {
+		n.wg.Go(func() {
+			for item := range n.queue {
+				n.deliver(item.hook, item.event)
+			}
+		})
+	}
+	n.wg.Go(func() {
+		defer close(n.queue)
+		var lastID uint64
+		for event := range n.subscription.C {
+			// Event IDs are consecutive, so a gap is exactly what the
+			// subscription dropped; the lag flag only covers the tail.
+			if lastID > 0 && event.ID > lastID+1 {
+				n.subscription.Lagged()
+				n.recordMissed(lastID+1, event.ID-1)
+			}
+			lastID = event.ID
+			for _, hook := range n.webhooks {
+				if len(hook.Events) > 0 && !slices.Contains(hook.Events, event.Type) {
+					continue
+				}
+				n.queue <- delivery{hook: hook, event: event}
+			}
+		}
+		if n.subscription.Lagged() {
+			n.recordMissed(lastID+1, 0)
+		}
+	})
+}
+
+// Stop stops listening for events and waits for queued deliveries.
+func (n *Notifier) Stop() {
+	if n.subscription != nil {
+		n.subscription.Close()
+	}
+	n.wg.Wait()
+}
+
+// recordMissed dead-letters events the subscription dropped. A zero to means
+// the range is open-ended because the notifier stopped before catching up.
+func (n *Notifier) recordMissed(from, to uint64) {
+	slog.Error("notifier fell behind and dropped events", "from_event_id", from, "to_event_id", to)
+	n.writeDeadLetter(deadLetter{
+		MissedFrom: from,
+		MissedTo:   to,
+		Error:      "notifier fell behind the event bus",
+		FailedAt:   time.Now(),
+	})
+}
+
+func (n *Notifier) deliver(hook WebhookConfig, event Event) {
+	var err error
+	for attempt := range n.retries + 1 {
+		if attempt > 0 {
+			time.Sleep(n.backoff << (attempt - 1))
+		}
+		if err = n.send(hook, event); err == nil {
+			return
+		}
+	}
+	slog.Error("failed to deliver webhook", "webhook", hook.Name, "event", event.Type, "event_id", event.ID, "error", err)
+	n.writeDeadLetter(deadLetter{
+		Webhook:  hook.Name,
+		URL:      hook.URL,
+		Event:    &event,
+		Error:    err.Error(),
+		FailedAt: time.Now(),
+	})
+}
+
+func (n *Notifier) send(hook WebhookConfig, event Event) error {
+	req, err := buildWebhookRequest(hook, event)
+	if err != nil {
+		return err
+	}
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (n *Notifier) writeDeadLetter(entry deadLetter) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	data, err := json.Marshal(entry)
+	if err != nil {
+		slog.Error("failed to encode dead letter", "error", err)
+		return
+	}
+	file, err := os.OpenFile(n.deadLetter, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		slog.Error("failed to open dead letter file", "file", n.deadLetter, "error", err)
+		return
+	}
+	defer file.Close()
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		slog.Error("failed to write dead letter", "file", n.deadLetter, "error", err)
+	}
+}
+
+// buildWebhookRequest renders an event with the webhook's payload template
+// and signs the body when the webhook has a secret.
+func buildWebhookRequest(hook WebhookConfig, event Event) (*http.Request, error) {
+	title, message, severity := describeEvent(event)
+	contentType := "application/json"
+	var body []byte
+	var err error
+	headers := map[string]string{}
+	switch hook.Format {
+	case "apprise":
+		body, err = json.Marshal(map[string]string{
+			"title": title,
+			"body":  message,
+			"type":  severity,
+		})
+	case "gotify":
+		priority := map[string]int{"failure": 8, "warning": 5}[severity]
+		body, err = json.Marshal(map[string]any{
+			"title":    title,
+			"message":  message,
+			"priority": max(priority, 2),
+		})
+	case "ntfy":
+		contentType = "text/plain; charset=utf-8"
+		body = []byte(message)
+		headers["Title"] = title
+		headers["Tags"] = map[string]string{"failure": "rotating_light", "warning": "warning", "success": "white_check_mark"}[severity]
+		headers["Priority"] = map[string]string{"failure": "high", "warning": "default"}[severity]
+	default:
+		body, err = json.Marshal(event)
+	}
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", "LinkSnapper")
+	req.Header.Set("X-LinkSnapper-Event", event.Type)
+	req.Header.Set("X-LinkSnapper-Delivery", fmt.Sprint(event.ID))
+	for key, value := range headers {
+		if value != "" {
+			req.Header.Set(key, value)
+		}
+	}
+	for key, value := range hook.Headers {
+		req.Header.Set(key, value)
+	}
+	if hook.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(hook.Secret))
+		mac.Write(body)
+		req.Header.Set("X-LinkSnapper-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	return req, nil
+}
+
+// describeEvent returns a title, message and Apprise-style severity (info,
+// success, warning or failure) for human-readable notification formats.
+func describeEvent(event Event) (string, string, string) {
+	switch data := event.Data.(type) {
+	case LinkHealthEvent:
+		name := linkLabel(data.Link)
+		detail := data.Link.Health.Error
+		if detail == "" && data.Link.Health.StatusCode != 0 {
+			detail = fmt.Sprintf("HTTP %d", data.Link.Health.StatusCode)
+		}
+		if event.Type == EventLinkDead {
+			return "Link is dead", fmt.Sprintf("%s keeps failing health checks: %s", name, detail), "failure"
+		}
+		severity := map[string]string{"healthy": "success", "degraded": "warning", "dead": "failure"}[data.Link.Health.State]
+		return "Link health changed", fmt.Sprintf("%s is now %s (was %s)", name, data.Link.Health.State, cmp.Or(data.PreviousState, "unknown")), cmp.Or(severity, "info")
+	case LinkEvent:
+		action := strings.TrimPrefix(event.Type, "link.")
+		return "Resource " + action, linkLabel(data.Link), "info"
+	case BookmarkEvent:
+		action := strings.TrimPrefix(event.Type, "bookmark.")
+		label := cmp.Or(data.Bookmark.Name, data.Bookmark.URL, data.Bookmark.ID)
+		return "Bookmark " + action, label, "info"
+	case ImportEvent:
+		kind := strings.TrimSuffix(event.Type, ".imported")
+		return "Import finished", fmt.Sprintf("%d %s imported (%s)", data.Count, kind, data.Mode), "success"
+	case HealthCheckEvent:
+		severity := "success"
+		if data.Job.Unhealthy > 0 {
+			severity = "warning"
+		}
+		return "Health check finished", fmt.Sprintf("Checked %d links: %d healthy, %d unhealthy", data.Job.Checked, data.Job.Healthy, data.Job.Unhealthy), severity
+	}
+	return "LinkSnapper event", event.Type, "info"
+}
+
+func linkLabel(link Link) string {
+	if link.Name == "" || link.Name == link.URL {
+		return link.URL
+	}
+	return fmt.Sprintf("%s (%s)", link.Name, link.URL)
+}
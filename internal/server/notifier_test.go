@@ -0,0 +1,197 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNotifierDeliversSignedEvents(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- body
+	}))
+	defer receiver.Close()
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	notifier := NewNotifier(store.Events(), []WebhookConfig{{
+		Name:   "receiver",
+		URL:    receiver.URL,
+		Format: "json",
+		Secret: "s3cret",
+		Events: []string{EventLinkCreated},
+	}}, filepath.Join(t.TempDir(), "dead.jsonl"))
+	notifier.backoff = time.Millisecond
+	notifier.Start()
+	defer notifier.Stop()
+	if _, err := store.AddLink(Link{URL: "https://example.com", Name: "Example"}); err != nil {
+		t.Fatalf("AddLink() error = %v", err)
+	}
+
+	var request *http.Request
+	var body []byte
+	select {
+	case request = <-received:
+		body = <-bodies
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not delivered")
+	}
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(body)
+	if got, want := request.Header.Get("X-LinkSnapper-Signature"), "sha256="+hex.EncodeToString(mac.Sum(nil)); got != want {
+		t.Fatalf("signature = %q, want %q", got, want)
+	}
+	var event struct {
+		Type string    `json:"type"`
+		Data LinkEvent `json:"data"`
+	}
+	if err := json.Unmarshal(body, &event); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if event.Type != EventLinkCreated || event.Data.Link.URL != "https://example.com" {
+		t.Fatalf("event = %#v, want link.created for example.com", event)
+	}
+}
+
+func TestNotifierDeadLetter(t *testing.T) {
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+	bus := NewEventBus()
+	deadLetterFile := filepath.Join(t.TempDir(), "dead.jsonl")
+	notifier := NewNotifier(bus, []WebhookConfig{{Name: "broken", URL: receiver.URL, Format: "json"}}, deadLetterFile)
+	notifier.retries = 2
+	notifier.backoff = time.Millisecond
+	notifier.Start()
+	bus.Publish(EventLinksImported, ImportEvent{Mode: "merge", Count: 3})
+	time.Sleep(50 * time.Millisecond)
+	notifier.Stop()
+
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("delivery attempts = %d, want 3", got)
+	}
+	data, err := os.ReadFile(deadLetterFile)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	var entry deadLetter
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if entry.Webhook != "broken" || entry.Event.Type != EventLinksImported {
+		t.Fatalf("dead letter = %#v, want broken links.imported", entry)
+	}
+}
+
+func TestNotifierBacklog(t *testing.T) {
+	var inFlight, peak, delivered atomic.Int32
+	release := make(chan struct{})
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			seen := peak.Load()
+			if current <= seen || peak.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		<-release
+		delivered.Add(1)
+	}))
+	defer receiver.Close()
+	bus := NewEventBus()
+	deadLetterFile := filepath.Join(t.TempDir(), "dead.jsonl")
+	notifier := NewNotifier(bus, []WebhookConfig{{Name: "slow", URL: receiver.URL}}, deadLetterFile)
+	notifier.Start()
+	const published = 2000
+	for i := range published {
+		bus.Publish(EventLinksImported, ImportEvent{Mode: "merge", Count: i})
+	}
+	close(release)
+	time.Sleep(100 * time.Millisecond)
+	bus.Publish(EventLinksImported, ImportEvent{Mode: "merge"})
+	notifier.Stop()
+
+	if got := peak.Load(); got > notifierWorkers {
+		t.Fatalf("peak concurrent deliveries = %d, want at most %d", got, notifierWorkers)
+	}
+	data, err := os.ReadFile(deadLetterFile)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	// Every event is either delivered or accounted for in a missed range.
+	missed := uint64(0)
+	var entry deadLetter
+	for line := range strings.Lines(string(data)) {
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+		if entry.Event != nil || entry.MissedFrom == 0 || entry.MissedTo < entry.MissedFrom {
+			t.Fatalf("dead letter = %#v, want a missed range", entry)
+		}
+		missed += entry.MissedTo - entry.MissedFrom + 1
+	}
+	if missed == 0 || missed+uint64(delivered.Load()) != published+1 {
+		t.Fatalf("missed %d and delivered %d of %d events", missed, delivered.Load(), published+1)
+	}
+}
+
+func TestWebhookTemplates(t *testing.T) {
+	event := Event{ID: 7, Type: EventLinkDead, Data: LinkHealthEvent{
+		Link:          Link{URL: "https://gone.example", Health: Health{State: "dead", StatusCode: 404}},
+		PreviousState: "degraded",
+	}}
+	tests := []struct {
+		format      string
+		contentType string
+		want        []string
+	}{
+		{format: "apprise", contentType: "application/json", want: []string{`"title":"Link is dead"`, `"type":"failure"`}},
+		{format: "gotify", contentType: "application/json", want: []string{`"priority":8`, `HTTP 404`}},
+		{format: "ntfy", contentType: "text/plain; charset=utf-8", want: []string{"https://gone.example keeps failing"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			req, err := buildWebhookRequest(WebhookConfig{URL: "http://localhost/hook", Format: tt.format}, event)
+			if err != nil {
+				t.Fatalf("buildWebhookRequest() error = %v", err)
+			}
+			body, _ := io.ReadAll(req.Body)
+			if req.Header.Get("Content-Type") != tt.contentType {
+				t.Fatalf("Content-Type = %q, want %q", req.Header.Get("Content-Type"), tt.contentType)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(body), want) {
+					t.Fatalf("body %s does not contain %s", body, want)
+				}
+			}
+			if tt.format == "ntfy" && (req.Header.Get("Title") != "Link is dead" || req.Header.Get("Priority") != "high") {
+				t.Fatalf("ntfy headers = %v", req.Header)
+			}
+		})
+	}
+}
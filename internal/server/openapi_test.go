@@ -0,0 +1,453 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tanq16/linksnapper/pkg/client"
+)
+
+type openAPIDoc struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas   map[string]map[string]any `json:"schemas"`
+		Responses map[string]map[string]any `json:"responses"`
+	} `json:"components"`
+}
+
+func loadOpenAPI(t *testing.T) openAPIDoc {
+	t.Helper()
+	var doc openAPIDoc
+	if err := json.Unmarshal(openAPISpec, &doc); err != nil {
+		t.Fatalf("Unmarshal(openapi.json) error = %v", err)
+	}
+	return doc
+}
+
+func TestOpenAPIDocumentsEveryRoute(t *testing.T) {
+	doc := loadOpenAPI(t)
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	srv := New("", 0, store, NewHealthChecker(store, time.Hour), t.TempDir())
+	if err := srv.Setup(); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+
+	var documented []string
+	for path, item := range doc.Paths {
+		for method := range item {
+			if method != "parameters" {
+				documented = append(documented, strings.ToUpper(method)+" "+path)
+			}
+		}
+	}
+	slices.Sort(documented)
+	registered := slices.Sorted(slices.Values(srv.apiRoutes))
+	if !slices.Equal(documented, registered) {
+		t.Fatalf("documented routes = %v\nregistered routes = %v", documented, registered)
+	}
+}
+
+// contractTransport checks every response the client receives against the
+// operation the OpenAPI document declares for the request.
+type contractTransport struct {
+	t   *testing.T
+	doc openAPIDoc
+}
+
+func (c contractTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err := c.doc.checkResponse(req.Method, strings.TrimPrefix(req.URL.Path, "/api/v1"), resp.StatusCode, body); err != nil {
+		c.t.Errorf("%s %s: %v", req.Method, req.URL.Path, err)
+	}
+	return resp, nil
+}
+
+func (d openAPIDoc) checkResponse(method, path string, status int, body []byte) error {
+	template, ok := d.matchPath(path)
+	if !ok {
+		return fmt.Errorf("path is not documented")
+	}
+	var operation struct {
+		Responses map[string]map[string]any `json:"responses"`
+	}
+	raw, ok := d.Paths[template][strings.ToLower(method)]
+	if !ok {
+		return fmt.Errorf("method is not documented for %s", template)
+	}
+	if err := json.Unmarshal(raw, &operation); err != nil {
+		return err
+	}
+	response, ok := operation.Responses[strconv.Itoa(status)]
+	if !ok {
+		return fmt.Errorf("status %d is not documented: %s", status, body)
+	}
+	if ref, ok := response["$ref"].(string); ok {
+		response = d.Components.Responses[strings.TrimPrefix(ref, "#/components/responses/")]
+	}
+	content, _ := response["content"].(map[string]any)
+	media, ok := content["application/json"].(map[string]any)
+	if !ok {
+		return nil
+	}
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return fmt.Errorf("response is not JSON: %v", err)
+	}
+	return d.validate(media["schema"].(map[string]any), value, "$")
+}
+
+// matchPath finds the documented path template for a concrete path,
+// preferring literal segments over parameters.
+func (d openAPIDoc) matchPath(path string) (string, bool) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	best, bestLiterals := "", -1
+	for _, template := range slices.Sorted(maps.Keys(d.Paths)) {
+		parts := strings.Split(strings.Trim(template, "/"), "/")
+		if len(parts) != len(segments) {
+			continue
+		}
+		literals := 0
+		matched := true
+		for i, part := range parts {
+			switch {
+			case strings.HasPrefix(part, "{"):
+			case part == segments[i]:
+				literals++
+			default:
+				matched = false
+			}
+		}
+		if matched && literals > bestLiterals {
+			best, bestLiterals = template, literals
+		}
+	}
+	return best, bestLiterals >= 0
+}
+
+func (d openAPIDoc) validate(schema map[string]any, value any, at string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		return d.validate(d.Components.Schemas[strings.TrimPrefix(ref, "#/components/schemas/")], value, at)
+	}
+	if options, ok := schema["oneOf"].([]any); ok {
+		for _, option := range options {
+			if d.validate(option.(map[string]any), value, at) == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s matches none of oneOf", at)
+	}
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s = %T, want object", at, value)
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		required, _ := schema["required"].([]any)
+		for _, name := range required {
+			if _, ok := object[name.(string)]; !ok {
+				return fmt.Errorf("%s is missing required %q", at, name)
+			}
+		}
+		for name, field := range object {
+			if property, ok := properties[name].(map[string]any); ok {
+				if err := d.validate(property, field, at+"."+name); err != nil {
+					return err
+				}
+				continue
+			}
+			switch additional := schema["additionalProperties"].(type) {
+			case map[string]any:
+				if err := d.validate(additional, field, at+"."+name); err != nil {
+					return err
+				}
+			case bool:
+				if !additional {
+					return fmt.Errorf("%s has undocumented property %q", at, name)
+				}
+			}
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return fmt.Errorf("%s = %T, want array", at, value)
+		}
+		for i, item := range items {
+			if err := d.validate(schema["items"].(map[string]any), item, fmt.Sprintf("%s[%d]", at, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s = %T, want string", at, value)
+		}
+		if enum, ok := schema["enum"].([]any); ok && !slices.Contains(enum, any(text)) {
+			return fmt.Errorf("%s = %q, want one of %v", at, text, enum)
+		}
+	case "integer":
+		number, ok := value.(float64)
+		if !ok || number != math.Trunc(number) {
+			return fmt.Errorf("%s = %v, want integer", at, value)
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return fmt.Errorf("%s = %T, want number", at, value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s = %T, want boolean", at, value)
+		}
+	}
+	return nil
+}
+
+func TestOpenAPIContract(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer target.Close()
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	srv := New("", 0, store, NewHealthChecker(store, time.Hour), t.TempDir())
+	if err := srv.Setup(); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	httpServer := httptest.NewServer(srv.httpServer.Handler)
+	defer httpServer.Close()
+	api := client.New(httpServer.URL, &http.Client{Transport: contractTransport{t: t, doc: loadOpenAPI(t)}})
+	ctx := context.Background()
+
+	if err := api.Health(ctx); err != nil {
+		t.Fatalf("Health() error = %v", err)
+	}
+	link, err := api.CreateLink(ctx, client.LinkInput{URL: target.URL + "/page", Name: "Page", Path: []string{"Tech"}, Tags: []string{"docs"}})
+	if err != nil {
+		t.Fatalf("CreateLink() error = %v", err)
+	}
+	var apiErr *client.Error
+	if _, err := api.CreateLink(ctx, client.LinkInput{URL: link.URL}); !errors.As(err, &apiErr) || apiErr.Code != "link_exists" {
+		t.Fatalf("CreateLink() duplicate error = %v, want link_exists", err)
+	}
+	// Creating a link starts a check in the background; let it land before
+	// seeding health so it cannot overwrite the seeded records.
+	for deadline := time.Now().Add(2 * time.Second); ; time.Sleep(5 * time.Millisecond) {
+		if checked := store.GetLinks()[0].LastChecked; !checked.IsZero() {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("health check of the created link did not finish")
+		}
+	}
+	for range deadAfterFailures {
+		if err := store.UpdateHealth(link.ID, Health{
+			Status:           "unhealthy",
+			StatusCode:       http.StatusNotFound,
+			Method:           http.MethodGet,
+			FinalURL:         target.URL + "/moved",
+			Redirects:        []Redirect{{URL: target.URL + "/moved", StatusCode: http.StatusMovedPermanently}},
+			MovedPermanently: true,
+		}, time.Now()); err != nil {
+			t.Fatalf("UpdateHealth() error = %v", err)
+		}
+	}
+	if _, err := api.ListLinks(ctx); err != nil {
+		t.Fatalf("ListLinks() error = %v", err)
+	}
+	if _, err := api.QueryLinks(ctx, client.LinkQuery{Path: "Tech", Health: "dead", Since: time.Now().Add(-time.Hour), Sort: "name", Limit: 1, Fields: []string{"id", "url"}}); err != nil {
+		t.Fatalf("QueryLinks() error = %v", err)
+	}
+	if _, err := api.LinkHealth(ctx, link.ID); err != nil {
+		t.Fatalf("LinkHealth() error = %v", err)
+	}
+	if _, err := api.SetCategoryMeta(ctx, "Tech", client.CategoryMeta{Description: "Tools", Icon: "cpu", Order: 1, Pinned: true}); err != nil {
+		t.Fatalf("SetCategoryMeta() error = %v", err)
+	}
+	if _, err := api.DuplicateLinks(ctx); err != nil {
+		t.Fatalf("DuplicateLinks() error = %v", err)
+	}
+	if _, err := api.Categories(ctx); err != nil {
+		t.Fatalf("Categories() error = %v", err)
+	}
+	if err := api.DeleteCategoryMeta(ctx, "Tech"); err != nil {
+		t.Fatalf("DeleteCategoryMeta() error = %v", err)
+	}
+	if _, err := api.RenameCategory(ctx, client.CategoryRename{Path: "Tech", Name: "Technology", DryRun: true}); err != nil {
+		t.Fatalf("RenameCategory() error = %v", err)
+	}
+	if _, err := api.MoveCategory(ctx, client.CategoryMove{Path: "Tech", Parent: "Archive", DryRun: true}); err != nil {
+		t.Fatalf("MoveCategory() error = %v", err)
+	}
+	if _, err := api.DeadLinkReport(ctx, true); err != nil {
+		t.Fatalf("DeadLinkReport() error = %v", err)
+	}
+	if _, err := api.DeadLinkAction(ctx, client.DeadLinkActionRequest{Action: "move", IDs: []string{link.ID}}); err != nil {
+		t.Fatalf("DeadLinkAction() error = %v", err)
+	}
+	if _, err := api.ApplyLinkRedirect(ctx, link.ID); !errors.As(err, &apiErr) || apiErr.Code != "redirect_unhealthy" {
+		t.Fatalf("ApplyLinkRedirect() to a broken target error = %v, want redirect_unhealthy", err)
+	}
+	if err := store.UpdateHealth(link.ID, Health{
+		Status:           "healthy",
+		StatusCode:       http.StatusOK,
+		Method:           http.MethodHead,
+		FinalURL:         target.URL + "/moved",
+		Redirects:        []Redirect{{URL: target.URL + "/moved", StatusCode: http.StatusMovedPermanently}},
+		MovedPermanently: true,
+	}, time.Now()); err != nil {
+		t.Fatalf("UpdateHealth() error = %v", err)
+	}
+	if _, err := api.ApplyLinkRedirect(ctx, link.ID); err != nil {
+		t.Fatalf("ApplyLinkRedirect() error = %v", err)
+	}
+	if _, err := api.ApplyLinkRedirect(ctx, link.ID); !errors.As(err, &apiErr) || apiErr.Code != "no_redirect" {
+		t.Fatalf("ApplyLinkRedirect() again error = %v, want no_redirect", err)
+	}
+	if _, err := api.ApplyRedirects(ctx); err != nil {
+		t.Fatalf("ApplyRedirects() error = %v", err)
+	}
+	if _, err := api.UpdateLink(ctx, link.ID, client.LinkInput{URL: link.URL, Name: "Renamed", Path: []string{"Tech", "Go"}}); err != nil {
+		t.Fatalf("UpdateLink() error = %v", err)
+	}
+	job, err := api.CheckLink(ctx, link.ID)
+	if err != nil {
+		t.Fatalf("CheckLink() error = %v", err)
+	}
+	if _, err := api.HealthJob(ctx, job.ID); err != nil {
+		t.Fatalf("HealthJob() error = %v", err)
+	}
+	if _, err := api.StartHealthCheck(ctx, []string{"Tech"}); err != nil {
+		t.Fatalf("StartHealthCheck() error = %v", err)
+	}
+	if _, err := api.HealthJob(ctx, "missing"); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("HealthJob() missing error = %v, want 404", err)
+	}
+	if err := api.ImportLinks(ctx, []client.LinkInput{{URL: target.URL + "/imported", Path: []string{"Imported"}}}, "merge"); err != nil {
+		t.Fatalf("ImportLinks() error = %v", err)
+	}
+	if err := api.DeleteLink(ctx, link.ID); err != nil {
+		t.Fatalf("DeleteLink() error = %v", err)
+	}
+	imported, err := api.QueryLinks(ctx, client.LinkQuery{Path: "Imported"})
+	if err != nil || len(imported.Links) != 1 {
+		t.Fatalf("QueryLinks() = %+v, %v, want the imported link", imported, err)
+	}
+	reading := "reading"
+	progress := 40
+	if _, err := api.UpdateReadingState(ctx, imported.Links[0].ID, client.ReadingStatePatch{ReadState: &reading, Progress: &progress}); err != nil {
+		t.Fatalf("UpdateReadingState() error = %v", err)
+	}
+	if queue, err := api.Queue(ctx); err != nil || len(queue) != 1 {
+		t.Fatalf("Queue() = %d links, %v, want 1", len(queue), err)
+	}
+	if _, err := api.QueueStats(ctx, 7); err != nil {
+		t.Fatalf("QueueStats() error = %v", err)
+	}
+	starred := true
+	bulk, err := api.BulkLinks(ctx, []client.LinkOperation{
+		{Op: "move", ID: imported.Links[0].ID, Path: []string{"Imported", "Moved"}},
+		{Op: "state", ID: imported.Links[0].ID, State: &client.ReadingStatePatch{Starred: &starred}},
+		{Op: "addTags", ID: imported.Links[0].ID, Tags: []string{"go", "reference"}},
+		{Op: "removeTags", ID: imported.Links[0].ID, Tags: []string{"reference"}},
+		{Op: "delete", ID: "missing"},
+	}, false)
+	if err != nil || bulk.Applied != 4 || bulk.Failed != 1 {
+		t.Fatalf("BulkLinks() = %+v, %v, want 4 applied and 1 failed", bulk, err)
+	}
+	duplicate, err := api.CreateLink(ctx, client.LinkInput{URL: strings.Replace(imported.Links[0].URL, "http://", "https://", 1), Description: "Copy"})
+	if err != nil {
+		t.Fatalf("CreateLink() near-duplicate error = %v", err)
+	}
+	if merged, err := api.MergeLinks(ctx, imported.Links[0].ID, []string{duplicate.ID}); err != nil || merged.Description != "Copy" {
+		t.Fatalf("MergeLinks() = %+v, %v, want the description of the merged link", merged, err)
+	}
+
+	bookmark, err := api.CreateBookmark(ctx, client.BookmarkInput{Name: "Docs", URL: "https://docs.example", Folder: "Work/Reference"})
+	if err != nil {
+		t.Fatalf("CreateBookmark() error = %v", err)
+	}
+	if _, err := api.UpdateBookmark(ctx, bookmark.ID, client.BookmarkInput{Name: "Docs", URL: bookmark.URL, Icon: "book", Folder: "Work"}); err != nil {
+		t.Fatalf("UpdateBookmark() error = %v", err)
+	}
+	if err := api.ReorderBookmarks(ctx, client.BookmarkMove{ID: bookmark.ID, Folder: "Work", Index: new(int)}); err != nil {
+		t.Fatalf("ReorderBookmarks() error = %v", err)
+	}
+	if _, err := api.CreateBookmarkFolder(ctx, client.BookmarkFolderInput{Path: "Reading", Icon: "book"}); err != nil {
+		t.Fatalf("CreateBookmarkFolder() error = %v", err)
+	}
+	color := "#112233"
+	if _, err := api.UpdateBookmarkFolder(ctx, "Reading", client.BookmarkFolderPatch{Color: &color}); err != nil {
+		t.Fatalf("UpdateBookmarkFolder() error = %v", err)
+	}
+	if _, err := api.ListBookmarkFolders(ctx); err != nil {
+		t.Fatalf("ListBookmarkFolders() error = %v", err)
+	}
+	if err := api.DeleteBookmarkFolder(ctx, "Reading", ""); err != nil {
+		t.Fatalf("DeleteBookmarkFolder() error = %v", err)
+	}
+	copied, err := api.CreateBookmark(ctx, client.BookmarkInput{Name: "Docs copy", URL: "https://www.docs.example/", Folder: "Work"})
+	if err != nil {
+		t.Fatalf("CreateBookmark() copy error = %v", err)
+	}
+	if groups, err := api.DuplicateBookmarks(ctx); err != nil || len(groups) != 1 {
+		t.Fatalf("DuplicateBookmarks() = %+v, %v, want one group", groups, err)
+	}
+	if _, err := api.MergeBookmarks(ctx, bookmark.ID, []string{copied.ID}); err != nil {
+		t.Fatalf("MergeBookmarks() error = %v", err)
+	}
+	snapshot, err := api.BookmarkChanges(ctx, 0)
+	if err != nil || !snapshot.Reset {
+		t.Fatalf("BookmarkChanges() = %+v, %v, want a snapshot", snapshot, err)
+	}
+	synced, err := api.SyncBookmarks(ctx, client.BookmarkSyncRequest{Since: snapshot.Revision, Changes: []client.BookmarkChange{
+		{Op: "upsert", ID: "from-browser", Bookmark: &client.BookmarkLink{Name: "Bar", URL: "https://bar.example"}, Folder: "Toolbar", Index: new(int), UpdatedAt: time.Now()},
+		{Op: "delete", ID: "gone", UpdatedAt: time.Now()},
+	}})
+	if err != nil || synced.Reset || len(synced.Changes) != 2 {
+		t.Fatalf("SyncBookmarks() = %+v, %v, want the pushed upsert and delete back", synced, err)
+	}
+	if _, err := api.ListBookmarks(ctx); err != nil {
+		t.Fatalf("ListBookmarks() error = %v", err)
+	}
+	if _, err := api.Recent(ctx, time.Now().Add(-time.Hour), 10); err != nil {
+		t.Fatalf("Recent() error = %v", err)
+	}
+	exported, err := api.ExportBookmarks(ctx)
+	if err != nil {
+		t.Fatalf("ExportBookmarks() error = %v", err)
+	}
+	if err := api.ImportBookmarks(ctx, exported, "replace"); err != nil {
+		t.Fatalf("ImportBookmarks() error = %v", err)
+	}
+	if err := api.DeleteBookmark(ctx, bookmark.ID); err != nil {
+		t.Fatalf("DeleteBookmark() error = %v", err)
+	}
+	if err := api.DeleteBookmark(ctx, bookmark.ID); !errors.As(err, &apiErr) || apiErr.Code != "bookmark_not_found" {
+		t.Fatalf("DeleteBookmark() again error = %v, want bookmark_not_found", err)
+	}
+}
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+const maxQueueStatsDays = 365
+
+func (s *Server) handleLinkReadingState(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	var patch ReadingStatePatch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		writeError(w, r, ErrInvalidJSON)
+		return
+	}
+	if err := patch.validate(); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	link, err := s.store.UpdateReadingState(id, patch)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("update reading state of link %s: %w", id, err))
+		return
+	}
+	writeJSON(w, http.StatusOK, link)
+}
+
+// handleQueue lists the read-later queue, oldest first: unread and reading
+// links that are not archived.
+func (s *Server) handleQueue(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+	states := []string{"unread", "reading"}
+	if value := values.Get("readState"); value != "" {
+		if !slices.Contains(states, value) {
+			writeError(w, r, invalidRequest("readState must be unread or reading"))
+			return
+		}
+		states = []string{value}
+	}
+	path := splitLinkPath(values.Get("path"))
+	queue := slices.DeleteFunc(s.store.GetLinks(), func(link Link) bool {
+		return !slices.Contains(states, link.ReadState) || link.Archived || !hasPathPrefix(link.Path, path)
+	})
+	slices.SortStableFunc(queue, func(a, b Link) int {
+		return cmp.Or(queuedAt(a).Compare(queuedAt(b)), cmp.Compare(a.ID, b.ID))
+	})
+	if value := values.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxLinkPageSize {
+			writeError(w, r, invalidRequest(fmt.Sprintf("limit must be between 1 and %d", maxLinkPageSize)))
+			return
+		}
+		queue = queue[:min(limit, len(queue))]
+	}
+	writeJSON(w, http.StatusOK, queue)
+}
+
+func queuedAt(link Link) time.Time {
+	if link.QueuedAt.IsZero() {
+		return link.CreatedAt
+	}
+	return link.QueuedAt
+}
+
+// QueueStats counts links by reading state and estimates how the queue grew
+// over the last days.
+type QueueStats struct {
+	Unread   int        `json:"unread"`
+	Reading  int        `json:"reading"`
+	Read     int        `json:"read"`
+	Starred  int        `json:"starred"`
+	Archived int        `json:"archived"`
+	Trend    []QueueDay `json:"trend"`
+}
+
+// QueueDay is one UTC day: links queued and finished that day and the queue
+// size at its end. No history is stored; the days are worked out from the
+// queue and read times of the links as they are now, so deleted links, links
+// taken off the queue and earlier archiving are not reflected.
+type QueueDay struct {
+	Date     string `json:"date"`
+	Added    int    `json:"added"`
+	Finished int    `json:"finished"`
+	Size     int    `json:"size"`
+}
+
+func (s *Server) handleQueueStats(w http.ResponseWriter, r *http.Request) {
+	days := 30
+	if value := r.URL.Query().Get("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxQueueStatsDays {
+			writeError(w, r, invalidRequest(fmt.Sprintf("days must be between 1 and %d", maxQueueStatsDays)))
+			return
+		}
+		days = parsed
+	}
+	writeJSON(w, http.StatusOK, buildQueueStats(s.store.GetLinks(), days, time.Now().UTC()))
+}
+
+func buildQueueStats(links []Link, days int, now time.Time) QueueStats {
+	stats := QueueStats{Trend: make([]QueueDay, days)}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	first := today.AddDate(0, 0, 1-days)
+	for i := range stats.Trend {
+		stats.Trend[i].Date = first.AddDate(0, 0, i).Format(time.DateOnly)
+	}
+	dayIndex := func(t time.Time) int {
+		if t.IsZero() || t.Before(first) {
+			return -1
+		}
+		return int(t.UTC().Sub(first) / (24 * time.Hour))
+	}
+	for _, link := range links {
+		switch link.ReadState {
+		case "unread":
+			stats.Unread++
+		case "reading":
+			stats.Reading++
+		case "read":
+			stats.Read++
+		}
+		if link.Starred {
+			stats.Starred++
+		}
+		if link.Archived {
+			stats.Archived++
+		}
+		if link.ReadState == "" {
+			continue
+		}
+		queued := queuedAt(link)
+		if i := dayIndex(queued); i >= 0 && i < days {
+			stats.Trend[i].Added++
+		}
+		if i := dayIndex(link.ReadAt); i >= 0 && i < days {
+			stats.Trend[i].Finished++
+		}
+		// Archived links are left out of /queue, so they do not count
+		// towards its size either.
+		if link.Archived {
+			continue
+		}
+		for i := range stats.Trend {
+			end := first.AddDate(0, 0, i+1)
+			if queued.Before(end) && (link.ReadAt.IsZero() || !link.ReadAt.Before(end)) {
+				stats.Trend[i].Size++
+			}
+		}
+	}
+	return stats
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadingStatePatch(t *testing.T) {
+	now := time.Date(2026, time.October, 8, 12, 0, 0, 0, time.UTC)
+	earlier := now.Add(-time.Hour)
+	str := func(value string) *string { return &value }
+	num := func(value int) *int { return &value }
+	tests := []struct {
+		name  string
+		state ReadingState
+		patch ReadingStatePatch
+		want  ReadingState
+	}{
+		{name: "queue", patch: ReadingStatePatch{ReadState: str("unread")}, want: ReadingState{ReadState: "unread", QueuedAt: now}},
+		{name: "progress starts reading", state: ReadingState{ReadState: "unread", QueuedAt: earlier}, patch: ReadingStatePatch{Progress: num(30)}, want: ReadingState{ReadState: "reading", Progress: 30, QueuedAt: earlier}},
+		{name: "finish", state: ReadingState{ReadState: "reading", Progress: 30, QueuedAt: earlier}, patch: ReadingStatePatch{ReadState: str("read")}, want: ReadingState{ReadState: "read", Progress: 100, QueuedAt: earlier, ReadAt: now}},
+		{name: "requeue", state: ReadingState{ReadState: "read", Progress: 100, QueuedAt: earlier, ReadAt: earlier}, patch: ReadingStatePatch{ReadState: str("unread")}, want: ReadingState{ReadState: "unread", QueuedAt: now}},
+		{name: "dequeue keeps star", state: ReadingState{ReadState: "reading", Progress: 50, Starred: true, QueuedAt: earlier}, patch: ReadingStatePatch{ReadState: str("")}, want: ReadingState{Starred: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := tt.state
+			tt.patch.apply(&state, now)
+			if state != tt.want {
+				t.Fatalf("apply() = %+v, want %+v", state, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadLaterQueue(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	srv := New("", 0, store, NewHealthChecker(store, time.Hour), t.TempDir())
+	if err := srv.Setup(); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		srv.httpServer.Handler.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return rec
+	}
+	var ids []string
+	for _, name := range []string{"first", "second", "third", "plain"} {
+		link, err := store.AddLink(Link{URL: "https://" + name + ".example", Path: []string{"Reading"}})
+		if err != nil {
+			t.Fatalf("AddLink() error = %v", err)
+		}
+		ids = append(ids, link.ID)
+	}
+	for _, id := range ids[:3] {
+		if rec := do(http.MethodPatch, "/api/v1/links/"+id+"/state", `{"readState":"unread"}`); rec.Code != http.StatusOK {
+			t.Fatalf("PATCH state status = %d, body %s", rec.Code, rec.Body.String())
+		}
+	}
+	do(http.MethodPatch, "/api/v1/links/"+ids[1]+"/state", `{"archived":true,"starred":true}`)
+	do(http.MethodPatch, "/api/v1/links/"+ids[2]+"/state", `{"progress":60}`)
+
+	// Editing a link must not drop it from the queue.
+	if err := store.UpdateLink(ids[0], Link{URL: "https://first.example", Name: "First", Path: []string{"Reading"}}); err != nil {
+		t.Fatalf("UpdateLink() error = %v", err)
+	}
+
+	var queue []Link
+	if err := json.Unmarshal(do(http.MethodGet, "/api/v1/queue", "").Body.Bytes(), &queue); err != nil {
+		t.Fatalf("Unmarshal(queue) error = %v", err)
+	}
+	if got := linkIDs(queue); !slices.Equal(got, []string{ids[0], ids[2]}) {
+		t.Fatalf("queue = %v, want first and third (second is archived)", got)
+	}
+	if queue[1].ReadState != "reading" || queue[1].Progress != 60 {
+		t.Fatalf("third link state = %+v, want reading at 60%%", queue[1].ReadingState)
+	}
+
+	var starred []Link
+	if err := json.Unmarshal(do(http.MethodGet, "/api/v1/links?starred=true", "").Body.Bytes(), &starred); err != nil {
+		t.Fatalf("Unmarshal(starred) error = %v", err)
+	}
+	if got := linkIDs(starred); !slices.Equal(got, []string{ids[1]}) {
+		t.Fatalf("starred links = %v, want %v", got, ids[1:2])
+	}
+
+	do(http.MethodPatch, "/api/v1/links/"+ids[0]+"/state", `{"readState":"read"}`)
+	var stats QueueStats
+	if err := json.Unmarshal(do(http.MethodGet, "/api/v1/queue/stats?days=3", "").Body.Bytes(), &stats); err != nil {
+		t.Fatalf("Unmarshal(stats) error = %v", err)
+	}
+	today := stats.Trend[len(stats.Trend)-1]
+	if stats.Unread != 1 || stats.Reading != 1 || stats.Read != 1 || stats.Starred != 1 || stats.Archived != 1 || len(stats.Trend) != 3 {
+		t.Fatalf("stats = %+v", stats)
+	}
+	if today.Added != 3 || today.Finished != 1 || today.Size != 1 {
+		t.Fatalf("today = %+v, want 3 added, 1 finished, size 1 (the archived link is not queued)", today)
+	}
+
+	for _, body := range []string{`{"readState":"later"}`, `{"progress":101}`} {
+		if rec := do(http.MethodPatch, "/api/v1/links/"+ids[0]+"/state", body); rec.Code != http.StatusBadRequest {
+			t.Errorf("PATCH %s status = %d, want 400", body, rec.Code)
+		}
+	}
+	if rec := do(http.MethodPatch, "/api/v1/links/missing/state", `{"starred":true}`); rec.Code != http.StatusNotFound {
+		t.Errorf("PATCH missing status = %d, want 404", rec.Code)
+	}
+}
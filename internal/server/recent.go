@@ -0,0 +1,94 @@
+package server
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+const defaultRecentLimit = 50
+
+// RecentItem is one entry of the recently added feed, which merges links and
+// bookmarks into a single list.
+type RecentItem struct {
+	Type      string    `json:"type"`
+	ID        string    `json:"id"`
+	Name      string    `json:"name,omitempty"`
+	URL       string    `json:"url"`
+	Path      []string  `json:"path"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (s *Server) handleRecent(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+	var since time.Time
+	if value := values.Get("since"); value != "" {
+		parsed, err := parseSince(value, time.Now())
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		since = parsed
+	}
+	limit := defaultRecentLimit
+	if value := values.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxLinkPageSize {
+			writeError(w, r, invalidRequest(fmt.Sprintf("limit must be between 1 and %d", maxLinkPageSize)))
+			return
+		}
+		limit = parsed
+	}
+	kind := values.Get("type")
+	if kind != "" && kind != "link" && kind != "bookmark" {
+		writeError(w, r, invalidRequest("type must be link or bookmark"))
+		return
+	}
+
+	items := []RecentItem{}
+	if kind != "bookmark" {
+		for _, link := range s.store.GetLinks() {
+			items = append(items, RecentItem{
+				Type:      "link",
+				ID:        link.ID,
+				Name:      link.Name,
+				URL:       link.URL,
+				Path:      link.Path,
+				CreatedAt: link.CreatedAt,
+				UpdatedAt: link.UpdatedAt,
+			})
+		}
+	}
+	if kind != "link" {
+		config, err := s.bookmarks.Load()
+		if err != nil {
+			writeError(w, r, fmt.Errorf("load bookmarks: %w", err))
+			return
+		}
+		forEachBookmark(&config, func(path []string, link *BookmarkLink) {
+			items = append(items, RecentItem{
+				Type:      "bookmark",
+				ID:        link.ID,
+				Name:      link.Name,
+				URL:       link.URL,
+				Path:      slices.Clone(path),
+				CreatedAt: link.CreatedAt,
+				UpdatedAt: link.UpdatedAt,
+			})
+		})
+	}
+	items = slices.DeleteFunc(items, func(item RecentItem) bool {
+		return item.CreatedAt.Before(since)
+	})
+	slices.SortStableFunc(items, func(a, b RecentItem) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(a.ID, b.ID))
+	})
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	writeJSON(w, http.StatusOK, items)
+}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestTimestampBackfill(t *testing.T) {
+	dataDir := t.TempDir()
+	modTime := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	files := map[string]string{
+		"links.json":     `[{"id":"old","url":"https://old.example","path":["Tech"],"health":{"status":""},"lastChecked":"0001-01-01T00:00:00Z"}]`,
+		"bookmarks.yaml": "bookmarks:\n  - category: Work\n    links:\n      - name: Docs\n        url: https://docs.example\n",
+	}
+	for name, content := range files {
+		file := filepath.Join(dataDir, name)
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+		if err := os.Chtimes(file, modTime, modTime); err != nil {
+			t.Fatalf("Chtimes() error = %v", err)
+		}
+	}
+
+	store, err := NewStore(dataDir)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	link := store.GetLinks()[0]
+	if !link.CreatedAt.Equal(modTime) || !link.UpdatedAt.Equal(modTime) {
+		t.Fatalf("backfilled link times = %v/%v, want %v", link.CreatedAt, link.UpdatedAt, modTime)
+	}
+	config, err := NewBookmarkStore(dataDir).Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	bookmark := config.Bookmarks[0].Links[0]
+	if !bookmark.CreatedAt.Equal(modTime) || !bookmark.UpdatedAt.Equal(modTime) {
+		t.Fatalf("backfilled bookmark times = %v/%v, want %v", bookmark.CreatedAt, bookmark.UpdatedAt, modTime)
+	}
+
+	// The migration is persisted, so later writes do not move the timestamps.
+	reopened, err := NewStore(dataDir)
+	if err != nil {
+		t.Fatalf("reopened NewStore() error = %v", err)
+	}
+	if got := reopened.GetLinks()[0].CreatedAt; !got.Equal(modTime) {
+		t.Fatalf("persisted link CreatedAt = %v, want %v", got, modTime)
+	}
+	if err := reopened.UpdateLink("old", Link{URL: link.URL, Name: "Renamed", Path: link.Path}); err != nil {
+		t.Fatalf("UpdateLink() error = %v", err)
+	}
+	updated := reopened.GetLinks()[0]
+	if !updated.CreatedAt.Equal(modTime) || !updated.UpdatedAt.After(modTime) {
+		t.Fatalf("updated link times = %v/%v, want CreatedAt kept and UpdatedAt advanced", updated.CreatedAt, updated.UpdatedAt)
+	}
+	bookmarks := NewBookmarkStore(dataDir)
+	edited, err := bookmarks.Update(bookmark.ID, "Work", BookmarkLink{Name: "Docs", URL: bookmark.URL})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if !edited.CreatedAt.Equal(modTime) || !edited.UpdatedAt.After(modTime) {
+		t.Fatalf("updated bookmark times = %v/%v, want CreatedAt kept and UpdatedAt advanced", edited.CreatedAt, edited.UpdatedAt)
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, time.October, 8, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "2026-10-01T00:00:00Z", want: time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)},
+		{value: "7d", want: now.AddDate(0, 0, -7)},
+		{value: "36h", want: now.Add(-36 * time.Hour)},
+		{value: "-1h", wantErr: true},
+		{value: "last week", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseSince(tt.value, now)
+		if (err != nil) != tt.wantErr || !got.Equal(tt.want) {
+			t.Errorf("parseSince(%q) = %v, %v, want %v (error %v)", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestRecentFeed(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	old := time.Now().AddDate(0, 0, -30).UTC()
+	if err := store.ImportLinks([]Link{{ID: "old", URL: "https://old.example", Path: []string{"Tech"}, CreatedAt: old}}, "merge"); err != nil {
+		t.Fatalf("ImportLinks() error = %v", err)
+	}
+	fresh, err := store.AddLink(Link{URL: "https://fresh.example", Path: []string{"Tech"}})
+	if err != nil {
+		t.Fatalf("AddLink() error = %v", err)
+	}
+	srv := New("", 0, store, NewHealthChecker(store, time.Hour), t.TempDir())
+	if err := srv.Setup(); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	bookmark, err := srv.bookmarks.Create("Work/Reference", BookmarkLink{Name: "Docs", URL: "https://docs.example"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	get := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		srv.httpServer.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+
+	tests := []struct {
+		target  string
+		wantIDs []string
+	}{
+		{target: "/api/v1/recent", wantIDs: []string{bookmark.ID, fresh.ID, "old"}},
+		{target: "/api/v1/recent?since=7d", wantIDs: []string{bookmark.ID, fresh.ID}},
+		{target: "/api/v1/recent?type=link&limit=1", wantIDs: []string{fresh.ID}},
+		{target: "/api/v1/links?since=7d", wantIDs: []string{fresh.ID}},
+	}
+	for _, tt := range tests {
+		rec := get(tt.target)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s status = %d, body %s", tt.target, rec.Code, rec.Body.String())
+		}
+		var items []struct {
+			ID   string   `json:"id"`
+			Path []string `json:"path"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &items); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+		var ids []string
+		for _, item := range items {
+			ids = append(ids, item.ID)
+		}
+		if !slices.Equal(ids, tt.wantIDs) {
+			t.Errorf("GET %s ids = %v, want %v", tt.target, ids, tt.wantIDs)
+		}
+		if tt.target == "/api/v1/recent" && !slices.Equal(items[0].Path, []string{"Work", "Reference"}) {
+			t.Errorf("bookmark path = %v, want [Work Reference]", items[0].Path)
+		}
+	}
+	for _, target := range []string{"/api/v1/recent?since=soon", "/api/v1/recent?type=tag", "/api/v1/links?since=soon"} {
+		if rec := get(target); rec.Code != http.StatusBadRequest {
+			t.Errorf("GET %s status = %d, want 400", target, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const archiveLookupConcurrency = 4
+
+type DeadLinkReport struct {
+	GeneratedAt time.Time      `json:"generatedAt"`
+	Total       int            `json:"total"`
+	ByType      map[string]int `json:"byType"`
+	Hosts       []DeadLinkHost `json:"hosts"`
+}
+
+type DeadLinkHost struct {
+	Host   string          `json:"host"`
+	Total  int             `json:"total"`
+	Groups []DeadLinkGroup `json:"groups"`
+}
+
+type DeadLinkGroup struct {
+	Type  string `json:"type"`
+	Links []Link `json:"links"`
+}
+
+type DeadLinkActionResult struct {
+	ID    string `json:"id"`
+	URL   string `json:"url,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// ArchiveResolver finds an archived copy of a URL that can replace a dead
+// link.
+type ArchiveResolver interface {
+	Resolve(ctx context.Context, rawURL string) (string, error)
+}
+
+type WaybackResolver struct {
+	client   *http.Client
+	endpoint string
+}
+
+func NewWaybackResolver() *WaybackResolver {
+	return &WaybackResolver{
+		client:   &http.Client{Timeout: 15 * time.Second},
+		endpoint: "https://archive.org/wayback/available",
+	}
+}
+
+func (r *WaybackResolver) Resolve(ctx context.Context, rawURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.endpoint+"?url="+url.QueryEscape(rawURL), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("wayback lookup returned %d", resp.StatusCode)
+	}
+	var body struct {
+		ArchivedSnapshots struct {
+			Closest struct {
+				Available bool   `json:"available"`
+				URL       string `json:"url"`
+			} `json:"closest"`
+		} `json:"archived_snapshots"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+	closest := body.ArchivedSnapshots.Closest
+	if !closest.Available || closest.URL == "" {
+		return "", ErrNoSnapshot
+	}
+	return strings.Replace(closest.URL, "http://", "https://", 1), nil
+}
+
+// BuildDeadLinkReport groups dead links by host and failure type. Degraded
+// links are included only when asked for, since they may still recover.
+func BuildDeadLinkReport(links []Link, includeDegraded bool) DeadLinkReport {
+	report := DeadLinkReport{
+		GeneratedAt: time.Now(),
+		ByType:      make(map[string]int),
+		Hosts:       []DeadLinkHost{},
+	}
+	hosts := make(map[string]map[string][]Link)
+	for _, link := range links {
+		if !isDeadLink(link, includeDegraded) {
+			continue
+		}
+		host := linkHost(link.URL)
+		errorType := classifyHealthError(link.Health)
+		if hosts[host] == nil {
+			hosts[host] = make(map[string][]Link)
+		}
+		hosts[host][errorType] = append(hosts[host][errorType], link)
+		report.ByType[errorType]++
+		report.Total++
+	}
+	for host, groups := range hosts {
+		entry := DeadLinkHost{Host: host}
+		for errorType, links := range groups {
+			entry.Groups = append(entry.Groups, DeadLinkGroup{Type: errorType, Links: links})
+			entry.Total += len(links)
+		}
+		slices.SortFunc(entry.Groups, func(a, b DeadLinkGroup) int {
+			return cmp.Compare(a.Type, b.Type)
+		})
+		report.Hosts = append(report.Hosts, entry)
+	}
+	slices.SortFunc(report.Hosts, func(a, b DeadLinkHost) int {
+		return cmp.Or(cmp.Compare(b.Total, a.Total), cmp.Compare(a.Host, b.Host))
+	})
+	return report
+}
+
+func (r DeadLinkReport) Links() []Link {
+	var links []Link
+	for _, host := range r.Hosts {
+		for _, group := range host.Groups {
+			links = append(links, group.Links...)
+		}
+	}
+	return links
+}
+
+func isDeadLink(link Link, includeDegraded bool) bool {
+	switch link.Health.State {
+	case "dead":
+		return true
+	case "degraded":
+		return includeDegraded
+	case "":
+		return includeDegraded && link.Health.Status == "unhealthy"
+	}
+	return false
+}
+
+func classifyHealthError(health Health) string {
+	message := strings.ToLower(health.Error)
+	switch {
+	case strings.Contains(message, "no such host") || strings.Contains(message, "lookup "):
+		return "dns"
+	case strings.Contains(message, "tls") || strings.Contains(message, "x509") || strings.Contains(message, "certificate"):
+		return "tls"
+	case strings.Contains(message, "timeout") || strings.Contains(message, "deadline exceeded"):
+		return "timeout"
+	case strings.Contains(message, "connection refused") || strings.Contains(message, "connection reset"):
+		return "connection"
+	case health.StatusCode == http.StatusNotFound || health.StatusCode == http.StatusGone:
+		return "not_found"
+	case health.StatusCode >= 500:
+		return "server_error"
+	case health.StatusCode >= 400:
+		return "client_error"
+	}
+	return "other"
+}
+
+func linkHost(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return rawURL
+	}
+	return strings.ToLower(parsed.Hostname())
+}
+
+func (s *Server) handleDeadLinkReport(w http.ResponseWriter, r *http.Request) {
+	includeDegraded := r.URL.Query().Get("include") == "degraded"
+	writeJSON(w, http.StatusOK, BuildDeadLinkReport(s.store.GetLinks(), includeDegraded))
+}
+
+func (s *Server) handleDeadLinkActions(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Action          string   `json:"action"`
+		IDs             []string `json:"ids"`
+		Path            []string `json:"path"`
+		IncludeDegraded bool     `json:"includeDegraded"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, r, ErrInvalidJSON)
+		return
+	}
+	if request.Action != "delete" && request.Action != "move" && request.Action != "wayback" {
+		writeError(w, r, invalidRequest("action must be delete, move or wayback"))
+		return
+	}
+	if request.Action == "move" && len(request.Path) == 0 {
+		request.Path = []string{"Archive", "Dead"}
+	}
+	targets := BuildDeadLinkReport(s.store.GetLinks(), request.IncludeDegraded).Links()
+	if len(request.IDs) > 0 {
+		targets = slices.DeleteFunc(targets, func(link Link) bool {
+			return !slices.Contains(request.IDs, link.ID)
+		})
+	}
+	var archived []archiveLookup
+	if request.Action == "wayback" {
+		archived = s.resolveArchives(r.Context(), targets)
+	}
+	results := make([]DeadLinkActionResult, 0, len(targets))
+	for i, link := range targets {
+		result := DeadLinkActionResult{ID: link.ID, URL: link.URL}
+		var lookup archiveLookup
+		if archived != nil {
+			lookup = archived[i]
+		}
+		if err := s.applyDeadLinkAction(request.Action, link, request.Path, lookup, &result); err != nil {
+			slog.ErrorContext(r.Context(), "failed to apply dead link action", "action", request.Action, "link_id", link.ID, "error", err)
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+// archiveLookup is the outcome of resolving one link's archived copy.
+type archiveLookup struct {
+	url string
+	err error
+}
+
+// resolveArchives looks up archived copies for links, a few at a time, so a
+// large report does not wait on one slow lookup after another.
+func (s *Server) resolveArchives(ctx context.Context, links []Link) []archiveLookup {
+	lookups := make([]archiveLookup, len(links))
+	semaphore := make(chan struct{}, archiveLookupConcurrency)
+	var wg sync.WaitGroup
+	for i, link := range links {
+		wg.Go(func() {
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			lookups[i].url, lookups[i].err = s.archive.Resolve(ctx, link.URL)
+		})
+	}
+	wg.Wait()
+	return lookups
+}
+
+func (s *Server) applyDeadLinkAction(action string, link Link, path []string, archived archiveLookup, result *DeadLinkActionResult) error {
+	switch action {
+	case "delete":
+		return s.store.DeleteLink(link.ID)
+	case "move":
+		link.Path = path
+		return s.store.UpdateLink(link.ID, link)
+	}
+	if archived.err != nil {
+		return archived.err
+	}
+	updated, err := s.store.ReplaceURL(link.ID, archived.url)
+	if err != nil {
+		return err
+	}
+	result.URL = updated.URL
+	return nil
+}
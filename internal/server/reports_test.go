@@ -0,0 +1,183 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type staticResolver map[string]string
+
+func (r staticResolver) Resolve(ctx context.Context, rawURL string) (string, error) {
+	if archived, ok := r[rawURL]; ok {
+		return archived, nil
+	}
+	return "", ErrNoSnapshot
+}
+
+func TestBuildDeadLinkReport(t *testing.T) {
+	links := []Link{
+		{ID: "dns", URL: "https://gone.example/a", Health: Health{State: "dead", Error: "dial tcp: lookup gone.example: no such host"}},
+		{ID: "404", URL: "https://site.example/a", Health: Health{State: "dead", StatusCode: 404}},
+		{ID: "404b", URL: "https://site.example/b", Health: Health{State: "dead", StatusCode: 410}},
+		{ID: "tls", URL: "https://site.example/c", Health: Health{State: "dead", Error: "tls: failed to verify certificate"}},
+		{ID: "slow", URL: "https://slow.example", Health: Health{State: "degraded", Error: "context deadline exceeded (Client.Timeout exceeded)"}},
+		{ID: "ok", URL: "https://ok.example", Health: Health{State: "healthy", StatusCode: 200}},
+	}
+	report := BuildDeadLinkReport(links, false)
+	if report.Total != 4 || len(report.Hosts) != 2 {
+		t.Fatalf("report = %d links over %d hosts, want 4 over 2", report.Total, len(report.Hosts))
+	}
+	if report.Hosts[0].Host != "site.example" || len(report.Hosts[0].Groups) != 2 {
+		t.Fatalf("first host = %#v, want site.example with two groups", report.Hosts[0])
+	}
+	if report.ByType["not_found"] != 2 || report.ByType["dns"] != 1 || report.ByType["tls"] != 1 {
+		t.Fatalf("byType = %#v", report.ByType)
+	}
+	if got := BuildDeadLinkReport(links, true); got.ByType["timeout"] != 1 {
+		t.Fatalf("byType with degraded = %#v, want one timeout", got.ByType)
+	}
+}
+
+func TestDeadLinkActions(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		check   func(t *testing.T, links []Link)
+		wantErr bool
+	}{
+		{
+			name: "move",
+			body: `{"action":"move"}`,
+			check: func(t *testing.T, links []Link) {
+				for _, link := range links {
+					if link.Health.State == "dead" && strings.Join(link.Path, "/") != "Archive/Dead" {
+						t.Fatalf("dead link path = %v, want Archive/Dead", link.Path)
+					}
+				}
+			},
+		},
+		{
+			name: "delete selected",
+			body: `{"action":"delete","ids":["a"]}`,
+			check: func(t *testing.T, links []Link) {
+				if len(links) != 2 || links[0].ID != "b" {
+					t.Fatalf("links after delete = %#v, want b and healthy", links)
+				}
+			},
+		},
+		{
+			name:    "wayback",
+			body:    `{"action":"wayback"}`,
+			wantErr: true,
+			check: func(t *testing.T, links []Link) {
+				if links[0].URL != "https://web.archive.org/web/2020/https://a.example" || links[1].URL != "https://b.example" {
+					t.Fatalf("links after wayback = %#v", links)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := NewStore(t.TempDir())
+			if err != nil {
+				t.Fatalf("NewStore() error = %v", err)
+			}
+			for _, link := range []Link{
+				{ID: "a", URL: "https://a.example", Path: []string{"Tech"}, Health: Health{State: "dead", StatusCode: 404}},
+				{ID: "b", URL: "https://b.example", Path: []string{"Tech"}, Health: Health{State: "dead", StatusCode: 404}},
+				{ID: "c", URL: "https://c.example", Path: []string{"Tech"}, Health: Health{State: "healthy"}},
+			} {
+				if _, err := store.AddLink(link); err != nil {
+					t.Fatalf("AddLink() error = %v", err)
+				}
+			}
+			srv := New("", 0, store, NewHealthChecker(store, 0), t.TempDir())
+			srv.archive = staticResolver{
+				"https://a.example": "https://web.archive.org/web/2020/https://a.example",
+				// b's archived copy is already saved as c, so it must not be applied.
+				"https://b.example": "https://c.example/",
+			}
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, "/api/reports/dead-links/actions", strings.NewReader(tt.body))
+			srv.handleDeadLinkActions(recorder, request)
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("status = %d, body %s", recorder.Code, recorder.Body)
+			}
+			var results []DeadLinkActionResult
+			if err := json.Unmarshal(recorder.Body.Bytes(), &results); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			failed := false
+			for _, result := range results {
+				failed = failed || result.Error != ""
+			}
+			if failed != tt.wantErr {
+				t.Fatalf("results = %#v, want errors %v", results, tt.wantErr)
+			}
+			tt.check(t, store.GetLinks())
+		})
+	}
+}
+
+func TestWaybackResolver(t *testing.T) {
+	archive := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("url") != "https://a.example" {
+			w.Write([]byte(`{"archived_snapshots":{}}`))
+			return
+		}
+		w.Write([]byte(`{"archived_snapshots":{"closest":{"available":true,"url":"http://web.archive.org/web/2020/https://a.example","status":"200"}}}`))
+	}))
+	defer archive.Close()
+	resolver := NewWaybackResolver()
+	resolver.endpoint = archive.URL
+	got, err := resolver.Resolve(context.Background(), "https://a.example")
+	if err != nil || got != "https://web.archive.org/web/2020/https://a.example" {
+		t.Fatalf("Resolve() = %q, %v", got, err)
+	}
+	if _, err := resolver.Resolve(context.Background(), "https://b.example"); err != ErrNoSnapshot {
+		t.Fatalf("Resolve() missing error = %v, want ErrNoSnapshot", err)
+	}
+}
+
+type slowResolver struct {
+	inFlight, peak atomic.Int32
+}
+
+func (r *slowResolver) Resolve(ctx context.Context, rawURL string) (string, error) {
+	current := r.inFlight.Add(1)
+	defer r.inFlight.Add(-1)
+	for {
+		seen := r.peak.Load()
+		if current <= seen || r.peak.CompareAndSwap(seen, current) {
+			break
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	return "https://web.archive.org/web/2020/" + rawURL, nil
+}
+
+func TestResolveArchivesConcurrency(t *testing.T) {
+	resolver := &slowResolver{}
+	srv := &Server{archive: resolver}
+	links := make([]Link, 3*archiveLookupConcurrency)
+	for i := range links {
+		links[i] = Link{URL: fmt.Sprintf("https://%d.example", i)}
+	}
+	lookups := srv.resolveArchives(context.Background(), links)
+	for i, lookup := range lookups {
+		if lookup.err != nil || lookup.url != "https://web.archive.org/web/2020/"+links[i].URL {
+			t.Fatalf("lookup %d = %+v, want the archived copy of %s", i, lookup, links[i].URL)
+		}
+	}
+	if peak := resolver.peak.Load(); peak < 2 || peak > archiveLookupConcurrency {
+		t.Fatalf("peak lookups in flight = %d, want between 2 and %d", peak, archiveLookupConcurrency)
+	}
+}
@@ -0,0 +1,169 @@
+package server
+
+import (
+	"cmp"
+	_ "embed"
+	"errors"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+//go:embed save.html
+var savePageSource string
+
+var savePageTemplate = template.Must(template.New("save").Parse(savePageSource))
+
+// sharedURLPattern finds a URL in shared text, which is where some apps put
+// it instead of the url parameter.
+var sharedURLPattern = regexp.MustCompile(`https?://\S+`)
+
+// saveOrigin rejects a confirmed save posted from another site, so that a
+// page cannot add links by submitting a form to the server.
+var saveOrigin = http.NewCrossOriginProtection()
+
+// savePage is what the confirm page shows: the link being saved, links that
+// already have the URL, and the outcome once it is submitted.
+type savePage struct {
+	URL         string
+	Name        string
+	Description string
+	Path        string
+	Paths       []string
+	Existing    *Link
+	Similar     []Link
+	Saved       *Link
+	Error       string
+}
+
+// handleSavePage answers the bookmarklet and the PWA share target with a
+// confirm page prefilled from url, title, text and path.
+func (s *Server) handleSavePage(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	page := savePage{
+		URL:  strings.TrimSpace(query.Get("url")),
+		Name: strings.TrimSpace(query.Get("title")),
+		Path: strings.Join(splitLinkPath(query.Get("path")), "/"),
+	}
+	if page.URL == "" {
+		page.URL = sharedURLPattern.FindString(query.Get("text"))
+	}
+	links := s.store.GetLinks()
+	page.Paths = linkPaths(links)
+	if page.URL == "" {
+		page.Error = "Nothing to save: no URL was shared."
+		s.renderSavePage(w, r, http.StatusBadRequest, page)
+		return
+	}
+	normalized, err := s.normalizer.Normalize(page.URL)
+	if err != nil || !isWebURL(normalized) {
+		page.Error = "The shared URL is not a valid http or https link."
+		s.renderSavePage(w, r, http.StatusBadRequest, page)
+		return
+	}
+	page.URL = normalized
+	page.Existing, page.Similar = s.savedMatches(links, normalized)
+	s.renderSavePage(w, r, http.StatusOK, page)
+}
+
+// handleSave saves the confirmed link through Store.AddLink, filling an empty
+// name or description from the page itself.
+func (s *Server) handleSave(w http.ResponseWriter, r *http.Request) {
+	if err := saveOrigin.Check(r); err != nil {
+		slog.WarnContext(r.Context(), "cross-site save rejected", "origin", r.Header.Get("Origin"), "error", err)
+		s.renderSavePage(w, r, http.StatusForbidden, savePage{Error: "The link was not saved: the form was sent from another site."})
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, 64<<10)
+	if err := r.ParseForm(); err != nil {
+		s.renderSavePage(w, r, http.StatusBadRequest, savePage{Error: "The form could not be read."})
+		return
+	}
+	page := savePage{
+		URL:         strings.TrimSpace(r.PostForm.Get("url")),
+		Name:        strings.TrimSpace(r.PostForm.Get("name")),
+		Description: strings.TrimSpace(r.PostForm.Get("description")),
+		Path:        strings.Join(splitLinkPath(r.PostForm.Get("path")), "/"),
+	}
+	normalized, err := s.normalizer.Normalize(page.URL)
+	if page.URL == "" || err != nil || !isWebURL(normalized) {
+		page.Paths = linkPaths(s.store.GetLinks())
+		page.Error = "The URL is not a valid http or https link."
+		s.renderSavePage(w, r, http.StatusBadRequest, page)
+		return
+	}
+	link := Link{URL: normalized, Name: page.Name, Description: page.Description, Path: splitLinkPath(page.Path)}
+	if len(link.Path) == 0 {
+		link.Path = []string{"Uncategorized"}
+	}
+	if link.Name == "" || link.Description == "" {
+		if meta, err := s.metadata.Fetch(r.Context(), link.URL); err != nil {
+			slog.DebugContext(r.Context(), "page metadata unavailable", "url", link.URL, "error", err)
+		} else {
+			link.Name = cmp.Or(link.Name, meta.Title)
+			link.Description = cmp.Or(link.Description, meta.Description)
+		}
+	}
+	created, err := s.store.AddLink(link)
+	if errors.Is(err, ErrLinkExists) {
+		page.Existing, page.Similar = s.savedMatches(s.store.GetLinks(), normalized)
+		s.renderSavePage(w, r, http.StatusConflict, page)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "save link failed", "url", link.URL, "error", err)
+		page.Error = "The link could not be saved."
+		s.renderSavePage(w, r, http.StatusInternalServerError, page)
+		return
+	}
+	s.health.StartJob([]Link{created}, nil, []string{created.ID})
+	s.renderSavePage(w, r, http.StatusCreated, savePage{Saved: &created})
+}
+
+// isWebURL reports whether rawURL is an absolute http or https URL, the only
+// kind the confirm page saves.
+func isWebURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+// savedMatches returns the link stored under the same normalised URL and the
+// ones that differ only by scheme or a leading www.
+func (s *Server) savedMatches(links []Link, normalized string) (*Link, []Link) {
+	var existing *Link
+	var similar []Link
+	near := s.normalizer.nearKey(normalized)
+	for _, link := range links {
+		switch {
+		case existing == nil && s.normalizer.key(link.URL) == normalized:
+			existing = &link
+		case s.normalizer.nearKey(link.URL) == near:
+			similar = append(similar, link)
+		}
+	}
+	return existing, similar
+}
+
+// linkPaths lists the paths in use, for suggestions on the confirm page.
+func linkPaths(links []Link) []string {
+	var paths []string
+	for _, link := range links {
+		if path := strings.Join(link.Path, "/"); path != "" && !slices.Contains(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+func (s *Server) renderSavePage(w http.ResponseWriter, r *http.Request, status int, page savePage) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := savePageTemplate.Execute(w, page); err != nil {
+		slog.ErrorContext(r.Context(), "render save page failed", "error", err)
+	}
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type staticMetadata map[string]PageMetadata
+
+func (m staticMetadata) Fetch(ctx context.Context, rawURL string) (PageMetadata, error) {
+	if meta, ok := m[rawURL]; ok {
+		return meta, nil
+	}
+	return PageMetadata{}, errors.New("no metadata")
+}
+
+func TestParsePageMetadata(t *testing.T) {
+	tests := []struct {
+		name string
+		page string
+		want PageMetadata
+	}{
+		{
+			name: "title and description",
+			page: `<html><head><title>  Go &amp; Tools
+				</title><meta name="description" content="Tooling notes"></head></html>`,
+			want: PageMetadata{Title: "Go & Tools", Description: "Tooling notes"},
+		},
+		{
+			name: "open graph wins",
+			page: `<head><TITLE>Site</TITLE><meta content='Plain' name='Description'>
+				<meta property="og:title" content="Article"><meta property="og:description" content="Summary"></head>`,
+			want: PageMetadata{Title: "Article", Description: "Summary"},
+		},
+		{
+			name: "nothing",
+			page: `<p>no head</p>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parsePageMetadata(tt.page); got != tt.want {
+				t.Fatalf("parsePageMetadata() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveRoutes(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	if _, err := store.AddLink(Link{URL: "https://saved.example/page", Name: "Saved page", Path: []string{"Reading"}}); err != nil {
+		t.Fatalf("AddLink() error = %v", err)
+	}
+	checker := NewHealthChecker(store, time.Hour)
+	checker.client.Timeout = 200 * time.Millisecond
+	checker.retries = 0
+	// Saving starts a health check in the background; let it write before
+	// the data directory is removed.
+	t.Cleanup(func() {
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+			if !slices.ContainsFunc(store.GetLinks(), func(link Link) bool { return link.Name != "Saved page" && link.LastChecked.IsZero() }) {
+				return
+			}
+		}
+		t.Errorf("health checks of the saved links did not finish")
+	})
+	srv := New("", 0, store, checker, t.TempDir())
+	srv.metadata = staticMetadata{"https://new.example/post": {Title: "Post title", Description: "From the page"}}
+	if err := srv.Setup(); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	do := func(method, target string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		srv.httpServer.Handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for _, tt := range []struct {
+		target   string
+		wantCode int
+		wantBody string
+	}{
+		{"/save?url=https://new.example/post%3Futm_source%3Dx&title=Post&path=Tech/+Go", http.StatusOK, `value="Tech/Go"`},
+		{"/save?title=Shared&text=Look+at+https://new.example/post+now", http.StatusOK, `value="https://new.example/post"`},
+		{"/save?url=https://saved.example/page/", http.StatusOK, "Already saved as <strong>Saved page</strong> in Reading"},
+		{"/save?url=http://www.saved.example/page", http.StatusOK, "Similar links are already saved"},
+		{"/save?title=Nothing", http.StatusBadRequest, "no URL was shared"},
+		{"/save?url=javascript:alert(1)", http.StatusBadRequest, "not a valid http or https link"},
+	} {
+		rec := do(http.MethodGet, tt.target, nil)
+		if rec.Code != tt.wantCode || !strings.Contains(rec.Body.String(), tt.wantBody) {
+			t.Fatalf("GET %s = %d, want %d with %q in\n%s", tt.target, rec.Code, tt.wantCode, tt.wantBody, rec.Body.String())
+		}
+	}
+
+	rec := do(http.MethodPost, "/save", url.Values{"url": {"https://new.example/post?fbclid=1"}, "path": {"Tech/Go"}})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST /save status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	links := store.GetLinks()
+	saved := links[len(links)-1]
+	if saved.URL != "https://new.example/post" || saved.Name != "Post title" || saved.Description != "From the page" || strings.Join(saved.Path, "/") != "Tech/Go" {
+		t.Fatalf("saved link = %+v, want the normalised URL with page metadata", saved)
+	}
+	if rec := do(http.MethodPost, "/save", url.Values{"url": {"https://new.example/post"}}); rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), "Already saved") {
+		t.Fatalf("POST /save duplicate = %d, body %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodPost, "/save", url.Values{"url": {"https://other.example/"}, "name": {"Mine"}}); rec.Code != http.StatusCreated {
+		t.Fatalf("POST /save without metadata status = %d, want 201", rec.Code)
+	}
+	for _, rawURL := range []string{"%zz", "javascript:alert(1)", "file:///etc/passwd", "/relative"} {
+		if rec := do(http.MethodPost, "/save", url.Values{"url": {rawURL}}); rec.Code != http.StatusBadRequest {
+			t.Fatalf("POST /save %q status = %d, want 400", rawURL, rec.Code)
+		}
+	}
+
+	for _, tt := range []struct {
+		name     string
+		header   string
+		value    string
+		wantCode int
+	}{
+		{"cross-site fetch", "Sec-Fetch-Site", "cross-site", http.StatusForbidden},
+		{"same-site fetch", "Sec-Fetch-Site", "same-site", http.StatusForbidden},
+		{"foreign origin", "Origin", "https://evil.example", http.StatusForbidden},
+		{"same origin", "Origin", "http://example.com", http.StatusCreated},
+	} {
+		target := "https://" + strings.ReplaceAll(tt.name, " ", "-") + ".example/page"
+		req := httptest.NewRequest(http.MethodPost, "/save", strings.NewReader(url.Values{"url": {target}, "name": {tt.name}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set(tt.header, tt.value)
+		rec := httptest.NewRecorder()
+		srv.httpServer.Handler.ServeHTTP(rec, req)
+		saved := slices.ContainsFunc(store.GetLinks(), func(link Link) bool { return link.URL == target })
+		if rec.Code != tt.wantCode || saved != (tt.wantCode == http.StatusCreated) {
+			t.Fatalf("POST /save %s = %d (saved %t), want %d", tt.name, rec.Code, saved, tt.wantCode)
+		}
+	}
+}
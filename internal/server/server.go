@@ -6,11 +6,17 @@ import (
 	"fmt"
 	"io/fs"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 //go:embed static
 var staticFiles embed.FS
 
+var apiPrefixes = []string{"/api/v1", "/api"}
+
 type Server struct {
 	host       string
 	port       int
@@ -18,17 +24,37 @@ type Server struct {
 	httpServer *http.Server
 	store      Store
 	bookmarks  *BookmarkStore
+	categories *CategoryMetaStore
+	health     *HealthChecker
+	archive    ArchiveResolver
+	metadata   MetadataFetcher
+	normalizer *URLNormalizer
 	dataDir    string
+	eventEpoch string
+	shutdown   chan struct{}
+	closeOnce  sync.Once
+	apiRoutes  []string
 }
 
-func New(host string, port int, store Store, dataDir string) *Server {
+func New(host string, port int, store Store, health *HealthChecker, dataDir string) *Server {
+	bookmarks := NewBookmarkStore(dataDir)
+	bookmarks.events = store.Events()
+	categories := NewCategoryMetaStore(dataDir)
+	categories.events = store.Events()
 	return &Server{
-		host:      host,
-		port:      port,
-		mux:       http.NewServeMux(),
-		store:     store,
-		bookmarks: NewBookmarkStore(dataDir),
-		dataDir:   dataDir,
+		host:       host,
+		port:       port,
+		mux:        http.NewServeMux(),
+		store:      store,
+		bookmarks:  bookmarks,
+		categories: categories,
+		health:     health,
+		archive:    NewWaybackResolver(),
+		metadata:   NewHTMLMetadataFetcher(),
+		normalizer: NewURLNormalizer(URLNormalization{}),
+		dataDir:    dataDir,
+		eventEpoch: strconv.FormatInt(time.Now().UnixNano(), 36),
+		shutdown:   make(chan struct{}),
 	}
 }
 
@@ -39,36 +65,99 @@ func (s *Server) Setup() error {
 	}
 	s.mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.FS(staticFS))))
 
-	s.mux.HandleFunc("/api/health", s.handleHealth)
-	s.mux.HandleFunc("/api/links", s.handleLinks)
-	s.mux.HandleFunc("/api/categories", s.handleCategories)
-	s.mux.HandleFunc("/api/bookmarks", s.handleBookmarks)
-	s.mux.HandleFunc("/api/bookmarks/", s.handleBookmarksPath)
-	s.mux.HandleFunc("/api/config", s.handleConfigRaw)
-	s.mux.HandleFunc("/api/links/", func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path == "/api/links/import" {
-			s.handleLinksImport(w, r)
-			return
-		}
-		switch r.Method {
-		case http.MethodDelete:
-			s.handleLinkDelete(w, r)
-		case http.MethodPut:
-			s.handleLinkEdit(w, r)
-		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		}
-	})
+	s.mux.HandleFunc("GET /metrics", s.handleMetrics)
+	s.mux.HandleFunc("GET /feeds/links.atom", s.handleAtomFeed)
+	s.mux.HandleFunc("GET /feeds/links.rss", s.handleRSSFeed)
+	s.mux.HandleFunc("GET /feeds/links.json", s.handleJSONFeed)
+	s.mux.HandleFunc("GET /save", s.handleSavePage)
+	s.mux.HandleFunc("POST /save", s.handleSave)
+
+	s.handleAPI("GET /health", s.handleHealth)
+	s.handleAPI("POST /health/check", s.handleHealthCheck)
+	s.handleAPI("POST /health/apply-redirects", s.handleApplyRedirects)
+	s.handleAPI("GET /health/jobs/{id}", s.handleHealthJob)
+	s.handleAPI("GET /links", s.handleListLinks)
+	s.handleAPI("POST /links", s.handleCreateLink)
+	s.handleAPI("POST /links/bulk", s.handleLinksBulk)
+	s.handleAPI("GET /links/duplicates", s.handleLinkDuplicates)
+	s.handleAPI("POST /links/import", s.handleLinksImport)
+	s.handleAPI("POST /links/merge", s.handleLinksMerge)
+	s.handleAPI("PUT /links/{id}", s.handleLinkEdit)
+	s.handleAPI("DELETE /links/{id}", s.handleLinkDelete)
+	s.handleAPI("GET /links/{id}/health", s.handleLinkHealth)
+	s.handleAPI("POST /links/{id}/check", s.handleLinkCheck)
+	s.handleAPI("POST /links/{id}/apply-redirect", s.handleLinkApplyRedirect)
+	s.handleAPI("PATCH /links/{id}/state", s.handleLinkReadingState)
+	s.handleAPI("GET /queue", s.handleQueue)
+	s.handleAPI("GET /queue/stats", s.handleQueueStats)
+	s.handleAPI("GET /categories", s.handleCategories)
+	s.handleAPI("POST /categories/rename", s.handleCategoryRename)
+	s.handleAPI("POST /categories/move", s.handleCategoryMove)
+	s.handleAPI("PUT /categories/{path...}", s.handleSetCategoryMeta)
+	s.handleAPI("DELETE /categories/{path...}", s.handleDeleteCategoryMeta)
+	s.handleAPI("GET /recent", s.handleRecent)
+	s.handleAPI("GET /reports/dead-links", s.handleDeadLinkReport)
+	s.handleAPI("POST /reports/dead-links/actions", s.handleDeadLinkActions)
+	s.handleAPI("GET /bookmarks", s.handleListBookmarks)
+	s.handleAPI("POST /bookmarks", s.handleCreateBookmark)
+	s.handleAPI("GET /bookmarks/duplicates", s.handleBookmarkDuplicates)
+	s.handleAPI("GET /bookmarks/export", s.handleBookmarksExport)
+	s.handleAPI("POST /bookmarks/import", s.handleBookmarksImport)
+	s.handleAPI("POST /bookmarks/merge", s.handleBookmarksMerge)
+	s.handleAPI("POST /bookmarks/reorder", s.handleBookmarksReorder)
+	s.handleAPI("GET /bookmarks/sync", s.handleBookmarkChanges)
+	s.handleAPI("POST /bookmarks/sync", s.handleBookmarkSync)
+	s.handleAPI("GET /bookmarks/folders", s.handleListBookmarkFolders)
+	s.handleAPI("POST /bookmarks/folders", s.handleCreateBookmarkFolder)
+	s.handleAPI("PATCH /bookmarks/folders/{path...}", s.handleUpdateBookmarkFolder)
+	s.handleAPI("DELETE /bookmarks/folders/{path...}", s.handleDeleteBookmarkFolder)
+	s.handleAPI("PUT /bookmarks/{id}", s.handleUpdateBookmark)
+	s.handleAPI("DELETE /bookmarks/{id}", s.handleDeleteBookmark)
+	s.handleAPI("GET /config", s.handleReadConfig)
+	s.handleAPI("POST /config", s.handleWriteConfig)
+	s.handleAPI("GET /events", s.handleEvents)
+	s.handleAPI("GET /openapi.json", s.handleOpenAPI)
+	s.mux.HandleFunc("/api/", s.handleAPIFallback)
 
 	s.mux.HandleFunc("/", s.handleIndex)
 
 	s.httpServer = &http.Server{
 		Addr:    fmt.Sprintf("%s:%d", s.host, s.port),
-		Handler: s.mux,
+		Handler: logRequests(instrument(s.mux)),
 	}
 	return nil
 }
 
+// handleAPI registers an API route given as "METHOD /path" under /api/v1 and
+// under the original unversioned /api prefix, which is kept as an alias.
+func (s *Server) handleAPI(pattern string, handler http.HandlerFunc) {
+	method, path, _ := strings.Cut(pattern, " ")
+	// Trailing {name...} wildcards are recorded as plain OpenAPI parameters.
+	s.apiRoutes = append(s.apiRoutes, strings.ReplaceAll(pattern, "...}", "}"))
+	for _, prefix := range apiPrefixes {
+		s.mux.HandleFunc(method+" "+prefix+path, handler)
+	}
+}
+
+// handleAPIFallback answers API requests that matched no route, reporting a
+// method mismatch when the path exists for other methods.
+func (s *Server) handleAPIFallback(w http.ResponseWriter, r *http.Request) {
+	var allowed []string
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		probe := r.Clone(r.Context())
+		probe.Method = method
+		if _, pattern := s.mux.Handler(probe); pattern != "/api/" {
+			allowed = append(allowed, method)
+		}
+	}
+	if len(allowed) > 0 {
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		writeError(w, r, ErrMethodNotAllowed)
+		return
+	}
+	writeError(w, r, ErrNotFound)
+}
+
 func (s *Server) Run() error {
 	if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		return err
@@ -76,7 +165,10 @@ func (s *Server) Run() error {
 	return nil
 }
 
+// Shutdown ends open event streams and stops the HTTP server. It is safe to
+// call more than once.
 func (s *Server) Shutdown(ctx context.Context) error {
+	s.closeOnce.Do(func() { close(s.shutdown) })
 	return s.httpServer.Shutdown(ctx)
 }
 
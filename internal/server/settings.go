@@ -0,0 +1,61 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/goccy/go-yaml"
+)
+
+type Settings struct {
+	Webhooks         []WebhookConfig  `yaml:"webhooks,omitempty" json:"webhooks,omitempty"`
+	URLNormalization URLNormalization `yaml:"urlNormalization,omitempty" json:"urlNormalization,omitzero"`
+}
+
+type WebhookConfig struct {
+	Name    string            `yaml:"name" json:"name"`
+	URL     string            `yaml:"url" json:"url"`
+	Format  string            `yaml:"format,omitempty" json:"format,omitempty"`
+	Secret  string            `yaml:"secret,omitempty" json:"-"`
+	Events  []string          `yaml:"events,omitempty" json:"events,omitempty"`
+	Headers map[string]string `yaml:"headers,omitempty" json:"-"`
+}
+
+var webhookFormats = []string{"json", "apprise", "ntfy", "gotify"}
+
+// LoadSettings reads {dataDir}/settings.yaml. A missing file yields the
+// defaults.
+func LoadSettings(dataDir string) (Settings, error) {
+	var settings Settings
+	data, err := os.ReadFile(filepath.Join(dataDir, "settings.yaml"))
+	if os.IsNotExist(err) {
+		return settings, nil
+	}
+	if err != nil {
+		return settings, err
+	}
+	if err := yaml.Unmarshal(data, &settings); err != nil {
+		return settings, err
+	}
+	for i := range settings.Webhooks {
+		hook := &settings.Webhooks[i]
+		if hook.URL == "" {
+			return settings, fmt.Errorf("webhook %d has no url", i+1)
+		}
+		if hook.Name == "" {
+			hook.Name = hook.URL
+		}
+		if hook.Format == "" {
+			hook.Format = "json"
+		}
+		if !slices.Contains(webhookFormats, hook.Format) {
+			return settings, fmt.Errorf("webhook %q has unknown format %q", hook.Name, hook.Format)
+		}
+	}
+	if err := settings.URLNormalization.validate(); err != nil {
+		return settings, err
+	}
+	return settings, nil
+}
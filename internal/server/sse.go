@@ -0,0 +1,113 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	sseClientBuffer     = 64
+	sseHeartbeatPeriod  = 25 * time.Second
+	sseResyncEventName  = "resync"
+	sseConnectEventName = "ready"
+)
+
+// handleEvents streams store events as Server-Sent Events. Event IDs carry
+// the bus epoch so that a client resuming across a server restart is told to
+// resynchronise instead of being replayed unrelated events.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, r, errors.New("streaming unsupported"))
+		return
+	}
+	lastID, resumable := s.parseLastEventID(r)
+	subscription, replay, complete := s.store.Events().SubscribeSince(lastID, sseClientBuffer)
+	defer subscription.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: 3000\n\n")
+	if !resumable || !complete {
+		writeSSE(w, "", sseResyncEventName, "{}")
+	} else {
+		writeSSE(w, "", sseConnectEventName, "{}")
+	}
+	for _, event := range replay {
+		s.writeEvent(w, event)
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeatPeriod)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.shutdown:
+			return
+		case event, ok := <-subscription.C:
+			if !ok {
+				return
+			}
+			if subscription.Lagged() {
+				writeSSE(w, "", sseResyncEventName, "{}")
+			}
+			s.writeEvent(w, event)
+			flusher.Flush()
+		case <-heartbeat.C:
+			if subscription.Lagged() {
+				writeSSE(w, "", sseResyncEventName, "{}")
+			}
+			fmt.Fprintf(w, ": ping\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+func (s *Server) writeEvent(w http.ResponseWriter, event Event) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		slog.Error("failed to encode event", "event_id", event.ID, "error", err)
+		return
+	}
+	writeSSE(w, fmt.Sprintf("%s-%d", s.eventEpoch, event.ID), event.Type, string(data))
+}
+
+// parseLastEventID returns the event ID to resume after. The boolean is false
+// when the client sent an ID from another server run.
+func (s *Server) parseLastEventID(r *http.Request) (uint64, bool) {
+	value := r.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = r.URL.Query().Get("lastEventId")
+	}
+	if value == "" {
+		return 0, true
+	}
+	epoch, id, ok := strings.Cut(value, "-")
+	if !ok || epoch != s.eventEpoch {
+		return 0, false
+	}
+	lastID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return lastID, true
+}
+
+func writeSSE(w http.ResponseWriter, id, event, data string) {
+	if id != "" {
+		fmt.Fprintf(w, "id: %s\n", id)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+}
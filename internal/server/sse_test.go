@@ -0,0 +1,119 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type sseMessage struct {
+	id    string
+	event string
+	data  string
+}
+
+func readSSE(t *testing.T, reader *bufio.Reader) sseMessage {
+	t.Helper()
+	var message sseMessage
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("ReadString() error = %v", err)
+		}
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case line == "" && message.event != "":
+			return message
+		case strings.HasPrefix(line, "id: "):
+			message.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			message.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			message.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func openEventStream(t *testing.T, url, lastEventID string) (*bufio.Reader, func()) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url+"/api/events", nil)
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /api/events error = %v", err)
+	}
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", resp.Header.Get("Content-Type"))
+	}
+	return bufio.NewReader(resp.Body), func() { resp.Body.Close() }
+}
+
+func TestEventStreamResume(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	srv := New("", 0, store, NewHealthChecker(store, time.Hour), t.TempDir())
+	if err := srv.Setup(); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	httpServer := httptest.NewServer(srv.mux)
+	defer httpServer.Close()
+
+	reader, closeStream := openEventStream(t, httpServer.URL, "")
+	if got := readSSE(t, reader); got.event != "ready" {
+		t.Fatalf("first event = %#v, want ready", got)
+	}
+	created, err := store.AddLink(Link{URL: "https://one.example"})
+	if err != nil {
+		t.Fatalf("AddLink() error = %v", err)
+	}
+	first := readSSE(t, reader)
+	if first.event != EventLinkCreated || !strings.Contains(first.data, created.ID) {
+		t.Fatalf("event = %#v, want link.created for %s", first, created.ID)
+	}
+	closeStream()
+
+	if err := store.DeleteLink(created.ID); err != nil {
+		t.Fatalf("DeleteLink() error = %v", err)
+	}
+	reader, closeStream = openEventStream(t, httpServer.URL, first.id)
+	defer closeStream()
+	if got := readSSE(t, reader); got.event != "ready" {
+		t.Fatalf("resumed first event = %#v, want ready", got)
+	}
+	if got := readSSE(t, reader); got.event != EventLinkDeleted {
+		t.Fatalf("replayed event = %#v, want link.deleted", got)
+	}
+
+	stale, closeStale := openEventStream(t, httpServer.URL, "previous-run-3")
+	defer closeStale()
+	if got := readSSE(t, stale); got.event != "resync" {
+		t.Fatalf("stale resume event = %#v, want resync", got)
+	}
+}
+
+func TestShutdownTwice(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	srv := New("", 0, store, NewHealthChecker(store, time.Hour), t.TempDir())
+	if err := srv.Setup(); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	for range 2 {
+		if err := srv.Shutdown(context.Background()); err != nil {
+			t.Fatalf("Shutdown() error = %v", err)
+		}
+	}
+}
@@ -24,6 +24,7 @@ document.addEventListener('DOMContentLoaded', () => {
         links: [],
         route: { view: 'bookmarks', path: [] },
         bookmarkEditID: null,
+        draggedBookmarkID: null,
         linkEditID: null,
         selectedIcon: 'bookmark',
         selectedColor: 'mauve',
@@ -59,6 +60,7 @@ document.addEventListener('DOMContentLoaded', () => {
         name: document.getElementById('name'),
         category: document.getElementById('category'),
         description: document.getElementById('description'),
+        tags: document.getElementById('tags'),
         saveLinkBtn: document.getElementById('saveLinkBtn'),
         categorySuggestions: document.getElementById('categorySuggestions'),
         toast: document.getElementById('toast'),
@@ -85,11 +87,19 @@ document.addEventListener('DOMContentLoaded', () => {
         toastTimer = setTimeout(() => elements.toast.classList.add('hidden'), 3500);
     }
 
+    async function errorMessage(response, fallback) {
+        const text = (await response.text()).trim();
+        try {
+            return JSON.parse(text).error?.message || text || fallback;
+        } catch {
+            return text || fallback;
+        }
+    }
+
     async function api(url, options = {}) {
         const response = await fetch(url, options);
         if (!response.ok) {
-            const message = (await response.text()).trim() || `Request failed (${response.status})`;
-            throw new Error(message);
+            throw new Error(await errorMessage(response, `Request failed (${response.status})`));
         }
         if (response.status === 204) return null;
         const text = await response.text();
@@ -135,15 +145,18 @@ document.addEventListener('DOMContentLoaded', () => {
                 icon: ALL_ICONS.includes(bookmark.icon) ? bookmark.icon : 'bookmark',
                 color: COLORS.includes(bookmark.color) ? bookmark.color : (COLORS.includes(category.color) ? category.color : 'mauve'),
             }));
-            (category.folders || []).forEach((folder) => {
+            const addFolders = (folders, parent) => (folders || []).forEach((folder) => {
+                const folderPath = `${parent}/${folder.name || 'Folder'}`;
                 (folder.links || []).forEach((bookmark, index) => flat.push({
                     ...bookmark,
                     id: String(bookmark.id ?? `legacy-${flat.length}-${index}`),
-                    folder: `${categoryName}/${folder.name || 'Folder'}`,
+                    folder: folderPath,
                     icon: ALL_ICONS.includes(bookmark.icon) ? bookmark.icon : (ALL_ICONS.includes(folder.icon) ? folder.icon : 'bookmark'),
                     color: COLORS.includes(bookmark.color) ? bookmark.color : (COLORS.includes(category.color) ? category.color : 'mauve'),
                 }));
+                addFolders(folder.folders, folderPath);
             });
+            addFolders(category.folders, categoryName);
         });
         return flat;
     }
@@ -160,7 +173,7 @@ document.addEventListener('DOMContentLoaded', () => {
 
     async function loadBookmarks() {
         try {
-            state.bookmarks = normalizeBookmarks(await api('/api/bookmarks'));
+            state.bookmarks = normalizeBookmarks(await api('/api/v1/bookmarks'));
             renderBookmarks();
         } catch (error) {
             state.bookmarks = [];
@@ -171,7 +184,7 @@ document.addEventListener('DOMContentLoaded', () => {
 
     async function loadLinks() {
         try {
-            state.links = normalizeLinks(await api('/api/links'));
+            state.links = normalizeLinks(await api('/api/v1/links'));
             renderResourcePathTree();
             renderResources();
         } catch (error) {
@@ -183,12 +196,13 @@ document.addEventListener('DOMContentLoaded', () => {
     }
 
     function bookmarkTree(bookmarks) {
-        const root = { name: '', items: [], children: new Map() };
+        const root = { name: '', path: '', items: [], children: new Map() };
         bookmarks.forEach((bookmark) => {
             const segments = String(bookmark.folder || 'Uncategorized').split('/').map((part) => part.trim()).filter(Boolean);
             let node = root;
             (segments.length ? segments : ['Uncategorized']).forEach((segment) => {
-                if (!node.children.has(segment)) node.children.set(segment, { name: segment, items: [], children: new Map() });
+                const path = node.path ? `${node.path}/${segment}` : segment;
+                if (!node.children.has(segment)) node.children.set(segment, { name: segment, path, items: [], children: new Map() });
                 node = node.children.get(segment);
             });
             node.items.push(bookmark);
@@ -199,7 +213,7 @@ document.addEventListener('DOMContentLoaded', () => {
     function bookmarkItemHTML(bookmark) {
         const icon = ALL_ICONS.includes(bookmark.icon) ? bookmark.icon : 'bookmark';
         const color = COLORS.includes(bookmark.color) ? bookmark.color : 'mauve';
-        return `<article class="bm-item group flex items-center gap-2 bg-base rounded-lg pl-3.5 pr-2 py-2 hover:bg-surface0 transition-colors">
+        return `<article draggable="true" data-bookmark-id="${escapeHTML(bookmark.id)}" class="bm-item group flex items-center gap-2 bg-base rounded-lg pl-3.5 pr-2 py-2 hover:bg-surface0 transition-colors">
             <a href="${escapeHTML(safeURL(bookmark.url))}" target="_blank" rel="noopener" class="flex items-center gap-2.5 min-w-0 flex-1 py-0.5">
                 <i data-lucide="${icon}" class="w-4 h-4 flex-shrink-0" style="color:var(--${color})"></i>
                 <span class="min-w-0">
@@ -220,10 +234,10 @@ document.addEventListener('DOMContentLoaded', () => {
     }
 
     function folderHTML(node, depth = 0) {
-        const childFolders = [...node.children.values()].sort((a, b) => a.name.localeCompare(b.name));
+        const childFolders = [...node.children.values()];
         const color = node.items.find((item) => COLORS.includes(item.color))?.color || 'peach';
         return `<details open class="${depth ? 'bm-folder' : ''}">
-            <summary class="flex items-center text-subtext1 hover:text-rosewater transition-colors mb-2 text-sm">
+            <summary data-drop-folder="${escapeHTML(node.path)}" class="flex items-center text-subtext1 hover:text-rosewater transition-colors mb-2 text-sm">
                 <i data-lucide="chevron-right" class="chevron-icon w-4 h-4 mr-2"></i>
                 <i data-lucide="folder" class="w-4 h-4 mr-2" style="color:var(--${color})"></i>${escapeHTML(node.name)}
             </summary>
@@ -241,13 +255,13 @@ document.addEventListener('DOMContentLoaded', () => {
             return !query || query.split(/\s+/).every((word) => searchable.includes(word));
         });
         const tree = bookmarkTree(visible);
-        const categories = [...tree.children.values()].sort((a, b) => a.name.localeCompare(b.name));
+        const categories = [...tree.children.values()];
         elements.bookmarksRoot.innerHTML = categories.map((category) => {
             const categoryColor = category.items.find((item) => COLORS.includes(item.color))?.color || 'sapphire';
-            const children = [...category.children.values()].sort((a, b) => a.name.localeCompare(b.name));
+            const children = [...category.children.values()];
             return `<section>
                 <details open>
-                    <summary class="font-semibold mb-3 flex items-center text-sm tracking-wide" style="color:var(--${categoryColor})">
+                    <summary data-drop-folder="${escapeHTML(category.path)}" class="font-semibold mb-3 flex items-center text-sm tracking-wide" style="color:var(--${categoryColor})">
                         <i data-lucide="chevron-right" class="chevron-icon w-4 h-4 mr-2"></i>${escapeHTML(category.name)}
                     </summary>
                     <div class="space-y-3">${bookmarkGridHTML(category.items)}${children.map((child) => folderHTML(child, 1)).join('')}</div>
@@ -314,7 +328,7 @@ document.addEventListener('DOMContentLoaded', () => {
         };
         const editing = state.bookmarkEditID !== null;
         try {
-            await api(editing ? `/api/bookmarks/${encodeURIComponent(state.bookmarkEditID)}` : '/api/bookmarks', {
+            await api(editing ? `/api/v1/bookmarks/${encodeURIComponent(state.bookmarkEditID)}` : '/api/v1/bookmarks', {
                 method: editing ? 'PUT' : 'POST',
                 headers: { 'Content-Type': 'application/json' },
                 body: JSON.stringify(record),
@@ -327,10 +341,37 @@ document.addEventListener('DOMContentLoaded', () => {
         }
     }
 
+    // Dropping a bookmark on another one places it before or after that one,
+    // depending on which half of the card it lands on; dropping it on a folder heading
+    // moves it to the end of that folder.
+    async function moveBookmark(event) {
+        const id = state.draggedBookmarkID;
+        state.draggedBookmarkID = null;
+        const target = event.target.closest('[data-bookmark-id], [data-drop-folder]');
+        if (!id || !target || target.dataset.bookmarkId === id) return;
+        event.preventDefault();
+        let move = { id, folder: target.dataset.dropFolder };
+        if (target.dataset.bookmarkId) {
+            const box = target.getBoundingClientRect();
+            const after = event.clientX > box.left + box.width / 2;
+            move = after ? { id, after: target.dataset.bookmarkId } : { id, before: target.dataset.bookmarkId };
+        }
+        try {
+            await api('/api/v1/bookmarks/reorder', {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify(move),
+            });
+            await loadBookmarks();
+        } catch (error) {
+            showToast(error.message, 'error');
+        }
+    }
+
     async function deleteBookmark(bookmark) {
         if (!window.confirm(`Delete "${bookmark.name || bookmark.url}"?`)) return;
         try {
-            await api(`/api/bookmarks/${encodeURIComponent(bookmark.id)}`, { method: 'DELETE' });
+            await api(`/api/v1/bookmarks/${encodeURIComponent(bookmark.id)}`, { method: 'DELETE' });
             await loadBookmarks();
             showToast('Bookmark deleted.', 'success');
         } catch (error) {
@@ -379,9 +420,13 @@ document.addEventListener('DOMContentLoaded', () => {
     }
 
     function healthPresentation(link) {
+        const state = String(link.health?.state || '').toLowerCase();
+        const detail = link.health?.error ? `: ${link.health.error}` : (link.health?.statusCode ? ` (${link.health.statusCode})` : '');
+        if (state === 'dead') return ['octagon-x', 'red', `Dead${detail}`];
+        if (state === 'degraded') return ['triangle-alert', 'yellow', `Degraded${detail}`];
         const status = String(link.health?.status || '').toLowerCase();
-        if (['unhealthy', 'down', 'error'].includes(status)) return ['triangle-alert', 'red', link.health?.error || 'Unhealthy'];
-        if (['pending', 'checking', 'unknown'].includes(status) || !status) return ['circle-help', 'overlay1', 'Health unknown'];
+        if (!state && ['unhealthy', 'down', 'error'].includes(status)) return ['triangle-alert', 'red', link.health?.error || 'Unhealthy'];
+        if (!state && (['pending', 'checking', 'unknown'].includes(status) || !status)) return ['circle-help', 'overlay1', 'Health unknown'];
         return ['check-circle', 'green', `Healthy${link.health?.statusCode ? ` (${link.health.statusCode})` : ''}`];
     }
 
@@ -393,8 +438,10 @@ document.addEventListener('DOMContentLoaded', () => {
                 <span class="block text-sm font-medium group-hover:text-mauve transition-colors truncate">${escapeHTML(link.name || link.url)}</span>
                 <span class="block text-xs text-subtext0 truncate">${escapeHTML(link.description || link.url)}</span>
             </a>
+            ${(link.tags || []).map((tag) => `<span class="text-[10px] text-lavender bg-surface0 rounded px-1.5 py-0.5 hidden sm:block flex-shrink-0">${escapeHTML(tag)}</span>`).join('')}
             <span class="text-[10px] font-mono text-overlay0 hidden md:block flex-shrink-0">${escapeHTML(hostname(link.url))}</span>
             <span class="action-buttons flex items-center gap-2 flex-shrink-0">
+                ${link.health?.movedPermanently ? `<button type="button" data-action="apply-redirect" data-id="${escapeHTML(link.id)}" class="text-subtext1 hover:text-peach" title="${escapeHTML(`Moved permanently to ${link.health.finalUrl}`)}"><i data-lucide="corner-down-right" class="w-3.5 h-3.5"></i></button>` : ''}
                 <button type="button" data-action="edit-link" data-id="${escapeHTML(link.id)}" class="text-subtext1 hover:text-blue" title="Edit"><i data-lucide="pen" class="w-3.5 h-3.5"></i></button>
                 <button type="button" data-action="delete-link" data-id="${escapeHTML(link.id)}" class="text-subtext1 hover:text-red" title="Delete"><i data-lucide="trash-2" class="w-3.5 h-3.5"></i></button>
             </span>
@@ -406,7 +453,7 @@ document.addEventListener('DOMContentLoaded', () => {
         const currentPath = state.route.path.join('/');
         const links = state.links.filter((link) => {
             const matchesPath = query || !currentPath || link.path.join('/') === currentPath;
-            const searchable = [link.name, link.description, link.url, ...link.path].join(' ').toLowerCase();
+            const searchable = [link.name, link.description, link.url, ...link.path, ...(link.tags || [])].join(' ').toLowerCase();
             return matchesPath && (!query || query.split(/\s+/).every((word) => searchable.includes(word)));
         });
         const title = query ? 'Search results' : (state.route.path.at(-1) || 'Library');
@@ -435,6 +482,7 @@ document.addEventListener('DOMContentLoaded', () => {
         elements.name.value = link?.name || '';
         elements.category.value = link?.path?.join('/') || (state.route.path.join('/') || '');
         elements.description.value = link?.description || '';
+        elements.tags.value = (link?.tags || []).join(', ');
         elements.saveLinkBtn.textContent = link ? 'Update Link' : 'Save Link';
         elements.linkFormPanel.classList.remove('hidden');
         document.getElementById('addLinkBtn').classList.add('hidden');
@@ -456,11 +504,12 @@ document.addEventListener('DOMContentLoaded', () => {
             name: elements.name.value.trim() || elements.url.value.trim(),
             description: elements.description.value.trim(),
             path: elements.category.value.split('/').map((part) => part.trim()).filter(Boolean),
+            tags: elements.tags.value.split(',').map((tag) => tag.trim()).filter(Boolean),
         };
         if (!record.path.length) record.path = ['Uncategorized'];
         const editing = state.linkEditID !== null;
         try {
-            await api(editing ? `/api/links/${encodeURIComponent(state.linkEditID)}` : '/api/links', {
+            await api(editing ? `/api/v1/links/${encodeURIComponent(state.linkEditID)}` : '/api/v1/links', {
                 method: editing ? 'PUT' : 'POST',
                 headers: { 'Content-Type': 'application/json' },
                 body: JSON.stringify(record),
@@ -476,7 +525,7 @@ document.addEventListener('DOMContentLoaded', () => {
     async function deleteLink(link) {
         if (!window.confirm(`Delete "${link.name || link.url}"?`)) return;
         try {
-            await api(`/api/links/${encodeURIComponent(link.id)}`, { method: 'DELETE' });
+            await api(`/api/v1/links/${encodeURIComponent(link.id)}`, { method: 'DELETE' });
             await loadLinks();
             showToast('Resource deleted.', 'success');
         } catch (error) {
@@ -484,6 +533,17 @@ document.addEventListener('DOMContentLoaded', () => {
         }
     }
 
+    async function applyRedirect(link) {
+        if (!window.confirm(`Replace ${link.url} with ${link.health.finalUrl}?`)) return;
+        try {
+            await api(`/api/v1/links/${encodeURIComponent(link.id)}/apply-redirect`, { method: 'POST' });
+            await loadLinks();
+            showToast('Redirect applied.', 'success');
+        } catch (error) {
+            showToast(error.message, 'error');
+        }
+    }
+
     function routeHashFor(path) {
         return `#resources${path.length ? `/${path.map(encodeURIComponent).join('/')}` : ''}`;
     }
@@ -514,7 +574,7 @@ document.addEventListener('DOMContentLoaded', () => {
     async function downloadFrom(endpoint, filename) {
         try {
             const response = await fetch(endpoint);
-            if (!response.ok) throw new Error((await response.text()).trim() || 'Export failed');
+            if (!response.ok) throw new Error(await errorMessage(response, 'Export failed'));
             const blob = await response.blob();
             const url = URL.createObjectURL(blob);
             const anchor = document.createElement('a');
@@ -537,7 +597,7 @@ document.addEventListener('DOMContentLoaded', () => {
             const key = kind === 'resources' ? 'links' : 'bookmarks';
             const records = Array.isArray(parsed) ? parsed : parsed[key];
             if (!Array.isArray(records)) throw new Error(`The file does not contain a ${key} array.`);
-            await api(kind === 'resources' ? '/api/links/import' : '/api/bookmarks/import', {
+            await api(kind === 'resources' ? '/api/v1/links/import' : '/api/v1/bookmarks/import', {
                 method: 'POST',
                 headers: { 'Content-Type': 'application/json' },
                 body: JSON.stringify({ mode: 'merge', [key]: records }),
@@ -577,6 +637,20 @@ document.addEventListener('DOMContentLoaded', () => {
         elements.iconMoreBtn.classList.toggle('is-expanded', state.iconsExpanded);
         renderIconPicker();
     });
+    elements.bookmarksRoot.addEventListener('dragstart', (event) => {
+        const item = event.target.closest('[data-bookmark-id]');
+        if (!item) return;
+        state.draggedBookmarkID = item.dataset.bookmarkId;
+        event.dataTransfer.effectAllowed = 'move';
+        event.dataTransfer.setData('text/plain', item.dataset.bookmarkId);
+    });
+    elements.bookmarksRoot.addEventListener('dragover', (event) => {
+        if (state.draggedBookmarkID && event.target.closest('[data-bookmark-id], [data-drop-folder]')) event.preventDefault();
+    });
+    elements.bookmarksRoot.addEventListener('drop', moveBookmark);
+    elements.bookmarksRoot.addEventListener('dragend', () => {
+        state.draggedBookmarkID = null;
+    });
     elements.bookmarksRoot.addEventListener('click', (event) => {
         const button = event.target.closest('[data-action]');
         if (!button) return;
@@ -602,6 +676,7 @@ document.addEventListener('DOMContentLoaded', () => {
         if (!link) return;
         if (button.dataset.action === 'edit-link') openLinkForm(link);
         if (button.dataset.action === 'delete-link') deleteLink(link);
+        if (button.dataset.action === 'apply-redirect') applyRedirect(link);
     });
     elements.category.addEventListener('input', () => {
         const query = elements.category.value.trim().toLowerCase();
@@ -622,8 +697,8 @@ document.addEventListener('DOMContentLoaded', () => {
     });
 
     const date = new Date().toISOString().slice(0, 10);
-    document.getElementById('exportResourcesBtn').addEventListener('click', () => downloadFrom('/api/links', `linksnapper-resources-${date}.json`));
-    document.getElementById('exportBookmarksBtn').addEventListener('click', () => downloadFrom('/api/bookmarks/export', `linksnapper-bookmarks-${date}.json`));
+    document.getElementById('exportResourcesBtn').addEventListener('click', () => downloadFrom('/api/v1/links', `linksnapper-resources-${date}.json`));
+    document.getElementById('exportBookmarksBtn').addEventListener('click', () => downloadFrom('/api/v1/bookmarks/export', `linksnapper-bookmarks-${date}.json`));
     document.getElementById('importResourcesBtn').addEventListener('click', () => document.getElementById('resourcesFileInput').click());
     document.getElementById('importBookmarksBtn').addEventListener('click', () => document.getElementById('bookmarksFileInput').click());
     document.getElementById('resourcesFileInput').addEventListener('change', async (event) => {
@@ -635,6 +710,33 @@ document.addEventListener('DOMContentLoaded', () => {
         event.target.value = '';
     });
 
+    const bookmarklet = document.getElementById('bookmarkletLink');
+    bookmarklet.href = `javascript:(()=>{window.open('${window.location.origin}/save?url='+encodeURIComponent(location.href)+'&title='+encodeURIComponent(document.title),'linksnapper','width=520,height=640')})()`;
+    bookmarklet.addEventListener('click', (event) => {
+        event.preventDefault();
+        showToast('Drag the button to your bookmarks bar to install it');
+    });
+
+    function subscribeToEvents() {
+        if (!('EventSource' in window)) return;
+        const timers = {};
+        const refresh = (kind) => {
+            clearTimeout(timers[kind]);
+            timers[kind] = setTimeout(kind === 'links' ? loadLinks : loadBookmarks, 250);
+        };
+        const source = new EventSource('/api/v1/events');
+        source.addEventListener('resync', () => {
+            refresh('links');
+            refresh('bookmarks');
+        });
+        ['link.created', 'link.updated', 'link.deleted', 'links.imported', 'links.moved', 'link.health_changed'].forEach((type) => {
+            source.addEventListener(type, () => refresh('links'));
+        });
+        ['bookmark.created', 'bookmark.updated', 'bookmark.deleted', 'bookmarks.imported', 'bookmarks.replaced', 'bookmarks.reordered', 'bookmark.folder_changed', 'bookmarks.synced'].forEach((type) => {
+            source.addEventListener(type, () => refresh('bookmarks'));
+        });
+    }
+
     if ('ResizeObserver' in window) {
         new ResizeObserver(() => {
             if (!state.iconsExpanded && fitIconCount() !== state.collapsedIconCount) renderIconPicker();
@@ -646,6 +748,7 @@ document.addEventListener('DOMContentLoaded', () => {
     renderColorPicker();
     renderIconPicker();
     Promise.all([loadBookmarks(), loadLinks()]);
+    subscribeToEvents();
     createIcons();
 });
 
@@ -2,12 +2,13 @@ package server
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"slices"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -17,16 +18,25 @@ type Store interface {
 	AddLink(link Link) (Link, error)
 	DeleteLink(id string) error
 	UpdateLink(id string, updated Link) error
+	UpdateHealth(id string, health Health, checked time.Time) error
+	UpdateReadingState(id string, patch ReadingStatePatch) (Link, error)
+	ApplyRedirect(id string) (Link, error)
+	ReplaceURL(id, rawURL string) (Link, error)
 	ImportLinks(links []Link, mode string) error
+	MovePath(from, to []string, merge, dryRun bool) (PathChange, error)
+	BulkUpdate(ops []LinkOperation, atomic bool) (BulkResult, error)
+	MergeLinks(request MergeRequest) (Link, error)
+	SetURLNormalizer(normalizer *URLNormalizer)
 	GetCategories() *Category
+	Events() *EventBus
 }
 
-var ErrLinkNotFound = errors.New("link not found")
-
 type JSONStore struct {
-	links []Link
-	mu    sync.RWMutex
-	file  string
+	links      []Link
+	mu         sync.RWMutex
+	file       string
+	events     *EventBus
+	normalizer *URLNormalizer
 }
 
 func NewStore(dataDir string) (Store, error) {
@@ -34,37 +44,86 @@ func NewStore(dataDir string) (Store, error) {
 		return nil, err
 	}
 	file := filepath.Join(dataDir, "links.json")
+	links, backfilled, err := readLinks(file)
+	if err != nil {
+		return nil, err
+	}
 	store := &JSONStore{
-		file:  file,
-		links: make([]Link, 0),
+		file:       file,
+		links:      links,
+		events:     NewEventBus(),
+		normalizer: NewURLNormalizer(URLNormalization{}),
 	}
-	if _, err := os.Stat(file); err == nil {
-		data, err := os.ReadFile(file)
-		if err != nil {
-			return nil, err
-		}
-		if err := json.Unmarshal(data, &store.links); err != nil {
+	if backfilled {
+		if err := store.saveToFile(); err != nil {
 			return nil, err
 		}
 	}
 	return store, nil
 }
 
+// LoadLinks reads the links saved in dataDir without creating or changing
+// any file, for commands that only report on them.
+func LoadLinks(dataDir string) ([]Link, error) {
+	links, _, err := readLinks(filepath.Join(dataDir, "links.json"))
+	return links, err
+}
+
+// readLinks reads a links file, backfilling missing timestamps in memory,
+// and reports whether any were backfilled. A missing file holds no links.
+func readLinks(file string) ([]Link, bool, error) {
+	links := make([]Link, 0)
+	info, err := os.Stat(file)
+	if err != nil {
+		return links, false, nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := json.Unmarshal(data, &links); err != nil {
+		return nil, false, err
+	}
+	return links, backfillLinkTimes(links, info.ModTime()), nil
+}
+
+// backfillLinkTimes stamps links saved before timestamps were tracked. The
+// file's modification time is the closest known bound on when they were added.
+func backfillLinkTimes(links []Link, stamp time.Time) bool {
+	changed := false
+	for i := range links {
+		if links[i].CreatedAt.IsZero() {
+			links[i].CreatedAt = stamp.UTC()
+			changed = true
+		}
+		if links[i].UpdatedAt.IsZero() {
+			links[i].UpdatedAt = links[i].CreatedAt
+			changed = true
+		}
+	}
+	return changed
+}
+
 func (s *JSONStore) AddLink(link Link) (Link, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	link.URL = s.normalizer.key(link.URL)
+	link.Tags = normalizeTags(link.Tags)
 	for _, existingLink := range s.links {
-		if existingLink.URL == link.URL {
-			return Link{}, fmt.Errorf("link already exists")
+		if s.normalizer.key(existingLink.URL) == link.URL {
+			return Link{}, ErrLinkExists
 		}
 	}
 	if link.ID == "" {
 		link.ID = uuid.NewString()
 	}
+	link.CreatedAt = time.Now().UTC()
+	link.UpdatedAt = link.CreatedAt
 	s.links = append(s.links, link)
 	if err := s.saveToFile(); err != nil {
 		return Link{}, err
 	}
+	s.events.Publish(EventLinkCreated, LinkEvent{Link: link.clone()})
 	return link, nil
 }
 
@@ -74,7 +133,11 @@ func (s *JSONStore) DeleteLink(id string) error {
 	for i, link := range s.links {
 		if link.ID == id {
 			s.links = slices.Delete(s.links, i, i+1)
-			return s.saveToFile()
+			if err := s.saveToFile(); err != nil {
+				return err
+			}
+			s.events.Publish(EventLinkDeleted, LinkEvent{Link: link.clone()})
+			return nil
 		}
 	}
 	return ErrLinkNotFound
@@ -86,17 +149,165 @@ func (s *JSONStore) UpdateLink(id string, updatedLink Link) error {
 	for i, link := range s.links {
 		if link.ID == id {
 			updatedLink.ID = id
-			if updatedLink.URL == link.URL {
+			updatedLink.URL = s.normalizer.key(updatedLink.URL)
+			updatedLink.Tags = normalizeTags(updatedLink.Tags)
+			updatedLink.CreatedAt = link.CreatedAt
+			updatedLink.UpdatedAt = time.Now().UTC()
+			updatedLink.ReadingState = link.ReadingState
+			// Links saved before normalisation keep their health when only
+			// the form of their URL changes.
+			if updatedLink.URL == s.normalizer.key(link.URL) {
 				updatedLink.Health = link.Health
 				updatedLink.LastChecked = link.LastChecked
+				updatedLink.HealthHistory = link.HealthHistory
+			} else {
+				updatedLink.HealthHistory = nil
 			}
 			s.links[i] = updatedLink
-			return s.saveToFile()
+			if err := s.saveToFile(); err != nil {
+				return err
+			}
+			s.events.Publish(EventLinkUpdated, LinkEvent{Link: updatedLink.clone()})
+			return nil
 		}
 	}
 	return ErrLinkNotFound
 }
 
+// UpdateHealth records a health check result. Unlike UpdateLink it never
+// touches user-editable fields.
+func (s *JSONStore) UpdateHealth(id string, health Health, checked time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.links {
+		if s.links[i].ID == id {
+			link := &s.links[i]
+			history := link.HealthHistory
+			if extra := len(history) + 1 - healthHistoryLimit; extra > 0 {
+				history = history[extra:]
+			}
+			// The old backing array may be shared with copies handed out by
+			// GetLinks and events, so the history is always rebuilt.
+			link.HealthHistory = append(slices.Clone(history), HealthRecord{
+				Status:     health.Status,
+				StatusCode: health.StatusCode,
+				Error:      health.Error,
+				CheckedAt:  checked,
+			})
+			health.State = deriveHealthState(link.HealthHistory)
+			previous := link.Health.State
+			link.Health = health
+			link.LastChecked = checked
+			if err := s.saveToFile(); err != nil {
+				return err
+			}
+			if health.State != previous {
+				event := LinkHealthEvent{Link: link.clone(), PreviousState: previous}
+				s.events.Publish(EventLinkHealthChanged, event)
+				if health.State == "dead" {
+					s.events.Publish(EventLinkDead, event)
+				}
+			}
+			return nil
+		}
+	}
+	return ErrLinkNotFound
+}
+
+// UpdateReadingState changes the read-later state of a link. Like
+// UpdateHealth it leaves UpdatedAt alone, which tracks edits to the link.
+func (s *JSONStore) UpdateReadingState(id string, patch ReadingStatePatch) (Link, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	index := slices.IndexFunc(s.links, func(link Link) bool {
+		return link.ID == id
+	})
+	if index == -1 {
+		return Link{}, ErrLinkNotFound
+	}
+	link := s.links[index]
+	patch.apply(&link.ReadingState, time.Now().UTC())
+	s.links[index] = link
+	if err := s.saveToFile(); err != nil {
+		return Link{}, err
+	}
+	s.events.Publish(EventLinkUpdated, LinkEvent{Link: link.clone()})
+	return link, nil
+}
+
+// ApplyRedirect rewrites a link's URL to the final URL recorded by its last
+// health check. The check must have passed, and no other link may already
+// use the same normalised URL.
+func (s *JSONStore) ApplyRedirect(id string) (Link, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	index := slices.IndexFunc(s.links, func(link Link) bool {
+		return link.ID == id
+	})
+	if index == -1 {
+		return Link{}, ErrLinkNotFound
+	}
+	link := s.links[index]
+	if link.Health.FinalURL == "" {
+		return Link{}, ErrNoRedirect
+	}
+	if link.Health.Status != "healthy" {
+		return Link{}, ErrRedirectUnhealthy
+	}
+	target := s.normalizer.key(link.Health.FinalURL)
+	if target == s.normalizer.key(link.URL) {
+		return Link{}, ErrNoRedirect
+	}
+	if slices.ContainsFunc(s.links, func(existing Link) bool {
+		return existing.ID != id && s.normalizer.key(existing.URL) == target
+	}) {
+		return Link{}, ErrLinkExists
+	}
+	link.URL = target
+	link.Health.FinalURL = ""
+	link.Health.Redirects = nil
+	link.Health.MovedPermanently = false
+	link.UpdatedAt = time.Now().UTC()
+	s.links[index] = link
+	if err := s.saveToFile(); err != nil {
+		return Link{}, err
+	}
+	s.events.Publish(EventLinkUpdated, LinkEvent{Link: link.clone()})
+	return link, nil
+}
+
+// ReplaceURL points a link at a different URL, such as an archived copy, and
+// clears its health so the next check starts afresh. It fails with
+// ErrLinkExists when another link already uses the same normalised URL.
+func (s *JSONStore) ReplaceURL(id, rawURL string) (Link, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	index := slices.IndexFunc(s.links, func(link Link) bool {
+		return link.ID == id
+	})
+	if index == -1 {
+		return Link{}, ErrLinkNotFound
+	}
+	target := s.normalizer.key(rawURL)
+	if slices.ContainsFunc(s.links, func(existing Link) bool {
+		return existing.ID != id && s.normalizer.key(existing.URL) == target
+	}) {
+		return Link{}, ErrLinkExists
+	}
+	link := s.links[index]
+	link.URL = target
+	link.Health = Health{}
+	link.LastChecked = time.Time{}
+	link.HealthHistory = nil
+	link.UpdatedAt = time.Now().UTC()
+	s.links[index] = link
+	if err := s.saveToFile(); err != nil {
+		return Link{}, err
+	}
+	s.events.Publish(EventLinkUpdated, LinkEvent{Link: link.clone()})
+	return link, nil
+}
+
 func (s *JSONStore) ImportLinks(imported []Link, mode string) error {
 	if mode != "merge" && mode != "replace" {
 		return fmt.Errorf("invalid import mode %q", mode)
@@ -109,22 +320,35 @@ func (s *JSONStore) ImportLinks(imported []Link, mode string) error {
 	if mode == "merge" {
 		links = append(links, s.links...)
 	}
+	now := time.Now().UTC()
 	usedIDs := make(map[string]bool, len(links)+len(imported))
+	// byKey indexes links by normalised URL, keeping the first of any
+	// duplicates saved before normalisation, so each URL is parsed once.
+	byKey := make(map[string]int, len(links)+len(imported))
 	for i := range links {
 		if links[i].ID == "" || usedIDs[links[i].ID] {
 			links[i].ID = uuid.NewString()
 		}
 		usedIDs[links[i].ID] = true
+		key := s.normalizer.key(links[i].URL)
+		if _, seen := byKey[key]; !seen {
+			byKey[key] = i
+		}
 	}
 	for _, incoming := range imported {
-		existingIndex := slices.IndexFunc(links, func(link Link) bool {
-			return link.URL == incoming.URL
-		})
-		if existingIndex >= 0 {
+		incoming.URL = s.normalizer.key(incoming.URL)
+		incoming.Tags = normalizeTags(incoming.Tags)
+		if existingIndex, ok := byKey[incoming.URL]; ok {
 			existing := links[existingIndex]
 			incoming.ID = existing.ID
 			incoming.Health = existing.Health
 			incoming.LastChecked = existing.LastChecked
+			incoming.HealthHistory = existing.HealthHistory
+			incoming.CreatedAt = existing.CreatedAt
+			incoming.UpdatedAt = now
+			if incoming.ReadingState == (ReadingState{}) {
+				incoming.ReadingState = existing.ReadingState
+			}
 			links[existingIndex] = incoming
 			continue
 		}
@@ -132,17 +356,125 @@ func (s *JSONStore) ImportLinks(imported []Link, mode string) error {
 			incoming.ID = uuid.NewString()
 		}
 		usedIDs[incoming.ID] = true
+		if incoming.CreatedAt.IsZero() {
+			incoming.CreatedAt = now
+		}
+		if incoming.UpdatedAt.IsZero() {
+			incoming.UpdatedAt = incoming.CreatedAt
+		}
+		byKey[incoming.URL] = len(links)
 		links = append(links, incoming)
 	}
 	s.links = links
-	return s.saveToFile()
+	if err := s.saveToFile(); err != nil {
+		return err
+	}
+	s.events.Publish(EventLinksImported, ImportEvent{Mode: mode, Count: len(imported)})
+	return nil
+}
+
+// MovePath rewrites the path prefix from to to on every link under it in a
+// single write. Moving onto an existing category needs merge. A dry run only
+// reports what would change.
+func (s *JSONStore) MovePath(from, to []string, merge, dryRun bool) (PathChange, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	change := PathChange{From: strings.Join(from, "/"), To: strings.Join(to, "/"), DryRun: dryRun}
+	if hasPathPrefix(to, from) {
+		return PathChange{}, invalidRequest("a category cannot be moved into itself")
+	}
+	for _, link := range s.links {
+		switch {
+		case hasPathPrefix(link.Path, from):
+			change.Affected++
+		case hasPathPrefix(link.Path, to):
+			change.Merged = true
+		}
+	}
+	if change.Affected == 0 {
+		return PathChange{}, ErrCategoryNotFound
+	}
+	if change.Merged && !merge {
+		return PathChange{}, ErrCategoryExists
+	}
+	if dryRun {
+		return change, nil
+	}
+	now := time.Now().UTC()
+	for i := range s.links {
+		link := &s.links[i]
+		if hasPathPrefix(link.Path, from) {
+			link.Path = slices.Concat(to, link.Path[len(from):])
+			link.UpdatedAt = now
+		}
+	}
+	if err := s.saveToFile(); err != nil {
+		return PathChange{}, err
+	}
+	s.events.Publish(EventLinksMoved, change)
+	return change, nil
+}
+
+// BulkUpdate applies the operations in order under one lock and saves once.
+// In atomic mode nothing is saved unless every operation succeeds.
+func (s *JSONStore) BulkUpdate(ops []LinkOperation, atomic bool) (BulkResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	type pending struct {
+		event string
+		link  Link
+	}
+	links := slices.Clone(s.links)
+	now := time.Now().UTC()
+	result := BulkResult{Atomic: atomic, Results: make([]LinkOperationResult, len(ops))}
+	var events []pending
+	for i, op := range ops {
+		item := LinkOperationResult{Index: i, Op: op.Op, ID: op.ID, Status: "ok"}
+		event, link, err := applyLinkOperation(&links, op, now)
+		if err != nil {
+			item.Status = "failed"
+			item.Code, item.Error = operationError(err)
+			result.Failed++
+		} else {
+			if event != EventLinkDeleted {
+				item.Link = &link
+			}
+			events = append(events, pending{event, link})
+			result.Applied++
+		}
+		result.Results[i] = item
+	}
+	if atomic && result.Failed > 0 {
+		for i := range result.Results {
+			if result.Results[i].Status == "ok" {
+				result.Results[i].Status = "rolled_back"
+				result.Results[i].Link = nil
+			}
+		}
+		result.Applied = 0
+		result.RolledBack = true
+		return result, nil
+	}
+	if result.Applied == 0 {
+		return result, nil
+	}
+	s.links = links
+	if err := s.saveToFile(); err != nil {
+		return BulkResult{}, err
+	}
+	for _, p := range events {
+		s.events.Publish(p.event, LinkEvent{Link: p.link.clone()})
+	}
+	return result, nil
 }
 
 func (s *JSONStore) GetLinks() []Link {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	links := make([]Link, len(s.links))
-	copy(links, s.links)
+	for i, link := range s.links {
+		links[i] = link.clone()
+	}
 	return links
 }
 
@@ -152,19 +484,31 @@ func (s *JSONStore) GetCategories() *Category {
 	root := NewCategory("root", []string{})
 	for _, link := range s.links {
 		current := root
-		currentPath := []string{}
+		current.Links++
+		current.Health.add(link.Health.State)
 		for _, segment := range link.Path {
-			currentPath = append(currentPath, segment)
-			if _, exists := current.Categories[segment]; !exists {
-				current.Categories[segment] = NewCategory(segment, currentPath)
-			}
-			current = current.Categories[segment]
+			current = current.child(segment)
+			current.Links++
+			current.Health.add(link.Health.State)
 		}
 	}
+	root.sortTree()
 	return root
 }
 
-func (s *JSONStore) saveToFile() error {
+func (s *JSONStore) SetURLNormalizer(normalizer *URLNormalizer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.normalizer = normalizer
+}
+
+func (s *JSONStore) Events() *EventBus {
+	return s.events
+}
+
+func (s *JSONStore) saveToFile() (err error) {
+	started := time.Now()
+	defer func() { metrics.observeStoreWrite("links", time.Since(started), err) }()
 	data, err := json.MarshalIndent(s.links, "", "  ")
 	if err != nil {
 		return err
@@ -0,0 +1,174 @@
+package server
+
+import (
+	"cmp"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// URLNormalization configures how link URLs are canonicalised before they are
+// stored and compared. TrackingParams replaces the default list when set; a
+// trailing * matches any suffix. TrailingSlash is "strip" (the default) or
+// "keep".
+type URLNormalization struct {
+	TrackingParams []string     `yaml:"trackingParams,omitempty" json:"trackingParams,omitempty"`
+	TrailingSlash  string       `yaml:"trailingSlash,omitempty" json:"trailingSlash,omitempty"`
+	KeepFragment   bool         `yaml:"keepFragment,omitempty" json:"keepFragment,omitempty"`
+	Domains        []DomainRule `yaml:"domains,omitempty" json:"domains,omitempty"`
+}
+
+// DomainRule keeps only the listed query parameters for a host and its
+// subdomains, e.g. v, t and list for youtube.com.
+type DomainRule struct {
+	Host       string   `yaml:"host" json:"host"`
+	KeepParams []string `yaml:"keepParams" json:"keepParams"`
+}
+
+var defaultTrackingParams = []string{
+	"utm_*", "fbclid", "gclid", "dclid", "gbraid", "wbraid", "msclkid", "yclid",
+	"mc_cid", "mc_eid", "igshid", "_hsenc", "_hsmi", "mkt_tok", "ref_src",
+}
+
+func (c URLNormalization) validate() error {
+	if c.TrailingSlash != "" && c.TrailingSlash != "strip" && c.TrailingSlash != "keep" {
+		return fmt.Errorf("urlNormalization.trailingSlash must be strip or keep, not %q", c.TrailingSlash)
+	}
+	for i, rule := range c.Domains {
+		if rule.Host == "" {
+			return fmt.Errorf("urlNormalization domain rule %d has no host", i+1)
+		}
+	}
+	return nil
+}
+
+type URLNormalizer struct {
+	config URLNormalization
+}
+
+func NewURLNormalizer(config URLNormalization) *URLNormalizer {
+	if config.TrackingParams == nil {
+		config.TrackingParams = defaultTrackingParams
+	}
+	config.TrailingSlash = cmp.Or(config.TrailingSlash, "strip")
+	for i := range config.Domains {
+		config.Domains[i].Host = strings.ToLower(strings.TrimPrefix(config.Domains[i].Host, "."))
+	}
+	return &URLNormalizer{config: config}
+}
+
+// Normalize lowercases the scheme and host, drops default ports, applies the
+// trailing slash setting, removes tracking parameters (or everything a
+// domain rule does not keep), sorts the remaining parameters and drops the
+// fragment unless configured otherwise.
+func (n *URLNormalizer) Normalize(rawURL string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", err
+	}
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	parsed.Host = strings.ToLower(parsed.Host)
+	if host, port, err := net.SplitHostPort(parsed.Host); err == nil &&
+		(parsed.Scheme == "http" && port == "80" || parsed.Scheme == "https" && port == "443") {
+		parsed.Host = host
+	}
+	if n.config.TrailingSlash == "strip" && parsed.Host != "" {
+		parsed.Path = strings.TrimRight(parsed.Path, "/")
+		parsed.RawPath = strings.TrimRight(parsed.RawPath, "/")
+	}
+	if parsed.RawQuery != "" {
+		values := parsed.Query()
+		keep := n.keptParams(parsed.Hostname())
+		for name := range values {
+			if keep != nil && !slices.Contains(keep, name) || keep == nil && n.tracking(name) {
+				values.Del(name)
+			}
+		}
+		parsed.RawQuery = values.Encode()
+	}
+	parsed.ForceQuery = false
+	if !n.config.KeepFragment {
+		parsed.Fragment, parsed.RawFragment = "", ""
+	}
+	return parsed.String(), nil
+}
+
+// key returns the normalised URL used to compare links, or the URL itself
+// when it does not parse.
+func (n *URLNormalizer) key(rawURL string) string {
+	normalized, err := n.Normalize(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return normalized
+}
+
+// nearKey extends key so that http and https and a leading www. also match.
+func (n *URLNormalizer) nearKey(rawURL string) string {
+	key := n.key(rawURL)
+	if _, rest, ok := strings.Cut(key, "://"); ok {
+		key = rest
+	}
+	return strings.TrimPrefix(key, "www.")
+}
+
+func (n *URLNormalizer) keptParams(host string) []string {
+	for _, rule := range n.config.Domains {
+		if host == rule.Host || strings.HasSuffix(host, "."+rule.Host) {
+			if rule.KeepParams == nil {
+				return []string{}
+			}
+			return rule.KeepParams
+		}
+	}
+	return nil
+}
+
+func (n *URLNormalizer) tracking(name string) bool {
+	name = strings.ToLower(name)
+	return slices.ContainsFunc(n.config.TrackingParams, func(pattern string) bool {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			return strings.HasPrefix(name, strings.ToLower(prefix))
+		}
+		return name == strings.ToLower(pattern)
+	})
+}
+
+// DuplicateGroup holds links whose URLs match after normalisation, ignoring
+// the scheme and a leading www.
+type DuplicateGroup struct {
+	Key   string `json:"key"`
+	Links []Link `json:"links"`
+}
+
+func findDuplicates(links []Link, normalizer *URLNormalizer) []DuplicateGroup {
+	groups := map[string][]Link{}
+	for _, link := range links {
+		key := normalizer.nearKey(link.URL)
+		groups[key] = append(groups[key], link)
+	}
+	duplicates := []DuplicateGroup{}
+	for key, group := range groups {
+		if len(group) > 1 {
+			duplicates = append(duplicates, DuplicateGroup{Key: key, Links: group})
+		}
+	}
+	slices.SortFunc(duplicates, func(a, b DuplicateGroup) int {
+		return cmp.Compare(a.Key, b.Key)
+	})
+	return duplicates
+}
+
+// SetURLNormalization replaces the default normalisation rules, normally with
+// the ones from settings.yaml.
+func (s *Server) SetURLNormalization(config URLNormalization) {
+	s.normalizer = NewURLNormalizer(config)
+	s.store.SetURLNormalizer(s.normalizer)
+}
+
+func (s *Server) handleLinkDuplicates(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, findDuplicates(s.store.GetLinks(), s.normalizer))
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestURLNormalizerNormalize(t *testing.T) {
+	tests := []struct {
+		name   string
+		config URLNormalization
+		input  string
+		want   string
+	}{
+		{name: "scheme host and port", input: "HTTPS://Example.COM:443/Docs/", want: "https://example.com/Docs"},
+		{name: "root slash", input: "http://example.com/", want: "http://example.com"},
+		{name: "keep slash", config: URLNormalization{TrailingSlash: "keep"}, input: "https://example.com/docs/", want: "https://example.com/docs/"},
+		{name: "essential params kept", input: "https://www.youtube.com/watch?v=abc&utm_source=x&fbclid=y", want: "https://www.youtube.com/watch?v=abc"},
+		{name: "params sorted", input: "https://search.example/?q=go&page=2", want: "https://search.example?page=2&q=go"},
+		{name: "fragment dropped", input: "https://example.com/a#intro", want: "https://example.com/a"},
+		{name: "fragment kept", config: URLNormalization{KeepFragment: true}, input: "https://example.com/a#intro", want: "https://example.com/a#intro"},
+		{name: "custom tracking list", config: URLNormalization{TrackingParams: []string{"ref"}}, input: "https://example.com/?ref=hn&utm_source=x", want: "https://example.com?utm_source=x"},
+		{
+			name:   "domain rule",
+			config: URLNormalization{Domains: []DomainRule{{Host: "YouTube.com", KeepParams: []string{"v", "t"}}}},
+			input:  "https://m.youtube.com/watch?v=abc&t=42&si=share&feature=shared",
+			want:   "https://m.youtube.com/watch?t=42&v=abc",
+		},
+		{
+			name:   "domain rule without params",
+			config: URLNormalization{Domains: []DomainRule{{Host: "shop.example"}}},
+			input:  "https://shop.example/item?ref=mail&variant=red",
+			want:   "https://shop.example/item",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewURLNormalizer(tt.config).Normalize(tt.input)
+			if err != nil {
+				t.Fatalf("Normalize() error = %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("Normalize(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestURLNormalizationInStore(t *testing.T) {
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	store.SetURLNormalizer(NewURLNormalizer(URLNormalization{}))
+	link, err := store.AddLink(Link{URL: "https://Example.com/page/?utm_medium=mail", Path: []string{"Tech"}})
+	if err != nil {
+		t.Fatalf("AddLink() error = %v", err)
+	}
+	if link.URL != "https://example.com/page" {
+		t.Fatalf("stored URL = %q, want normalised", link.URL)
+	}
+	if _, err := store.AddLink(Link{URL: "https://example.com/page#top"}); !errors.Is(err, ErrLinkExists) {
+		t.Fatalf("AddLink(duplicate) error = %v, want %v", err, ErrLinkExists)
+	}
+	if err := store.ImportLinks([]Link{{URL: "HTTPS://example.com/page?fbclid=1", Name: "Page"}, {URL: "http://www.example.com/page"}}, "merge"); err != nil {
+		t.Fatalf("ImportLinks() error = %v", err)
+	}
+	links := store.GetLinks()
+	if len(links) != 2 || links[0].Name != "Page" || links[0].ID != link.ID {
+		t.Fatalf("links after import = %+v, want the first merged and one near-duplicate added", links)
+	}
+	groups := findDuplicates(links, NewURLNormalizer(URLNormalization{}))
+	if len(groups) != 1 || groups[0].Key != "example.com/page" || len(groups[0].Links) != 2 {
+		t.Fatalf("findDuplicates() = %+v, want one group of two", groups)
+	}
+}
+
+func TestUpdateLegacyLinkKeepsHealth(t *testing.T) {
+	dir := t.TempDir()
+	legacy := `[{"id":"old","url":"https://Example.com/page/?utm_source=feed","path":["Tech"],"health":{"status":"healthy"},"healthHistory":[{"status":"healthy","checkedAt":"2026-01-02T00:00:00Z"}]}]`
+	if err := os.WriteFile(filepath.Join(dir, "links.json"), []byte(legacy), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	if err := store.UpdateLink("old", Link{URL: "https://Example.com/page/?utm_source=feed", Name: "Page", Path: []string{"Tech"}}); err != nil {
+		t.Fatalf("UpdateLink() error = %v", err)
+	}
+	link := store.GetLinks()[0]
+	if link.URL != "https://example.com/page" || link.Health.Status != "healthy" || len(link.HealthHistory) != 1 {
+		t.Fatalf("link after rename = %+v, want the normalised URL with its health kept", link)
+	}
+}
@@ -0,0 +1,465 @@
+// Package client wraps the LinkSnapper REST API described by
+// /api/openapi.json. The event stream and the deprecated raw YAML endpoints
+// are not covered.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Error is returned for every non-2xx response. Code is the machine-readable
+// code from the API error envelope.
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+	RequestID  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("linksnapper: %s (%d %s)", e.Message, e.StatusCode, e.Code)
+}
+
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// New returns a client for the server at baseURL, e.g. http://localhost:8080.
+// A nil httpClient uses http.DefaultClient.
+func New(baseURL string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{baseURL: strings.TrimRight(baseURL, "/") + "/api/v1", httpClient: httpClient}
+}
+
+func (c *Client) Health(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, "/health", nil, nil)
+}
+
+func (c *Client) ListLinks(ctx context.Context) ([]Link, error) {
+	var links []Link
+	err := c.do(ctx, http.MethodGet, "/links", nil, &links)
+	return links, err
+}
+
+// LinkQuery filters, sorts and pages the links returned by QueryLinks. Zero
+// values are omitted from the request.
+type LinkQuery struct {
+	Path      string
+	Tag       string
+	Health    string
+	Host      string
+	Since     time.Time
+	ReadState string
+	Starred   *bool
+	Archived  *bool
+	Sort      string
+	Order     string
+	Limit     int
+	Cursor    string
+	Fields    []string
+}
+
+type LinkPage struct {
+	Links      []Link
+	Total      int
+	NextCursor string
+}
+
+// QueryLinks returns one page of links. Pass NextCursor back as Cursor to
+// fetch the following page; it is empty on the last page.
+func (c *Client) QueryLinks(ctx context.Context, query LinkQuery) (LinkPage, error) {
+	values := url.Values{}
+	for key, value := range map[string]string{
+		"path":      query.Path,
+		"tag":       query.Tag,
+		"health":    query.Health,
+		"host":      query.Host,
+		"readState": query.ReadState,
+		"sort":      query.Sort,
+		"order":     query.Order,
+		"cursor":    query.Cursor,
+		"fields":    strings.Join(query.Fields, ","),
+	} {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+	if !query.Since.IsZero() {
+		values.Set("since", query.Since.Format(time.RFC3339))
+	}
+	if query.Starred != nil {
+		values.Set("starred", strconv.FormatBool(*query.Starred))
+	}
+	if query.Archived != nil {
+		values.Set("archived", strconv.FormatBool(*query.Archived))
+	}
+	if query.Limit > 0 {
+		values.Set("limit", strconv.Itoa(query.Limit))
+	}
+	path := "/links"
+	if len(values) > 0 {
+		path += "?" + values.Encode()
+	}
+	resp, err := c.send(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return LinkPage{}, err
+	}
+	defer resp.Body.Close()
+	page := LinkPage{NextCursor: resp.Header.Get("X-Next-Cursor")}
+	page.Total, _ = strconv.Atoi(resp.Header.Get("X-Total-Count"))
+	err = json.NewDecoder(resp.Body).Decode(&page.Links)
+	return page, err
+}
+
+func (c *Client) CreateLink(ctx context.Context, input LinkInput) (Link, error) {
+	var link Link
+	err := c.do(ctx, http.MethodPost, "/links", input, &link)
+	return link, err
+}
+
+func (c *Client) UpdateLink(ctx context.Context, id string, input LinkInput) (Link, error) {
+	var link Link
+	err := c.do(ctx, http.MethodPut, "/links/"+url.PathEscape(id), input, &link)
+	return link, err
+}
+
+func (c *Client) DeleteLink(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/links/"+url.PathEscape(id), nil, nil)
+}
+
+// ImportLinks merges links into the store, or replaces every link when mode
+// is "replace".
+func (c *Client) ImportLinks(ctx context.Context, links []LinkInput, mode string) error {
+	body := map[string]any{"links": links, "mode": mode}
+	return c.do(ctx, http.MethodPost, "/links/import", body, nil)
+}
+
+// BulkLinks applies the operations with a single write. With atomic set,
+// nothing is saved unless every operation succeeds.
+func (c *Client) BulkLinks(ctx context.Context, ops []LinkOperation, atomic bool) (BulkResult, error) {
+	var result BulkResult
+	body := map[string]any{"operations": ops, "atomic": atomic}
+	err := c.do(ctx, http.MethodPost, "/links/bulk", body, &result)
+	return result, err
+}
+
+// DuplicateLinks groups links whose URLs match after normalisation, ignoring
+// the scheme and a leading www.
+func (c *Client) DuplicateLinks(ctx context.Context) ([]DuplicateGroup, error) {
+	var groups []DuplicateGroup
+	err := c.do(ctx, http.MethodGet, "/links/duplicates", nil, &groups)
+	return groups, err
+}
+
+// MergeLinks folds the links in ids into the one with ID keep and deletes
+// them, returning the kept link.
+func (c *Client) MergeLinks(ctx context.Context, keep string, ids []string) (Link, error) {
+	var link Link
+	err := c.do(ctx, http.MethodPost, "/links/merge", MergeRequest{Keep: keep, IDs: ids}, &link)
+	return link, err
+}
+
+func (c *Client) LinkHealth(ctx context.Context, id string) (LinkHealth, error) {
+	var health LinkHealth
+	err := c.do(ctx, http.MethodGet, "/links/"+url.PathEscape(id)+"/health", nil, &health)
+	return health, err
+}
+
+func (c *Client) CheckLink(ctx context.Context, id string) (HealthJob, error) {
+	var job HealthJob
+	err := c.do(ctx, http.MethodPost, "/links/"+url.PathEscape(id)+"/check", nil, &job)
+	return job, err
+}
+
+func (c *Client) ApplyLinkRedirect(ctx context.Context, id string) (Link, error) {
+	var link Link
+	err := c.do(ctx, http.MethodPost, "/links/"+url.PathEscape(id)+"/apply-redirect", nil, &link)
+	return link, err
+}
+
+func (c *Client) UpdateReadingState(ctx context.Context, id string, patch ReadingStatePatch) (Link, error) {
+	var link Link
+	err := c.do(ctx, http.MethodPatch, "/links/"+url.PathEscape(id)+"/state", patch, &link)
+	return link, err
+}
+
+// Queue returns unread and reading links that are not archived, oldest first.
+func (c *Client) Queue(ctx context.Context) ([]Link, error) {
+	var links []Link
+	err := c.do(ctx, http.MethodGet, "/queue", nil, &links)
+	return links, err
+}
+
+func (c *Client) QueueStats(ctx context.Context, days int) (QueueStats, error) {
+	path := "/queue/stats"
+	if days > 0 {
+		path += "?days=" + strconv.Itoa(days)
+	}
+	var stats QueueStats
+	err := c.do(ctx, http.MethodGet, path, nil, &stats)
+	return stats, err
+}
+
+func (c *Client) Categories(ctx context.Context) (Category, error) {
+	var category Category
+	err := c.do(ctx, http.MethodGet, "/categories", nil, &category)
+	return category, err
+}
+
+// SetCategoryMeta replaces the metadata of the category at path.
+func (c *Client) SetCategoryMeta(ctx context.Context, path string, meta CategoryMeta) (CategoryMeta, error) {
+	var saved CategoryMeta
+	err := c.do(ctx, http.MethodPut, "/categories/"+escapeFolderPath(path), meta, &saved)
+	return saved, err
+}
+
+func (c *Client) DeleteCategoryMeta(ctx context.Context, path string) error {
+	return c.do(ctx, http.MethodDelete, "/categories/"+escapeFolderPath(path), nil, nil)
+}
+
+func (c *Client) RenameCategory(ctx context.Context, rename CategoryRename) (PathChange, error) {
+	var change PathChange
+	err := c.do(ctx, http.MethodPost, "/categories/rename", rename, &change)
+	return change, err
+}
+
+func (c *Client) MoveCategory(ctx context.Context, move CategoryMove) (PathChange, error) {
+	var change PathChange
+	err := c.do(ctx, http.MethodPost, "/categories/move", move, &change)
+	return change, err
+}
+
+// Recent returns links and bookmarks created since the given time, newest
+// first. A zero since and limit use the server defaults.
+func (c *Client) Recent(ctx context.Context, since time.Time, limit int) ([]RecentItem, error) {
+	values := url.Values{}
+	if !since.IsZero() {
+		values.Set("since", since.Format(time.RFC3339))
+	}
+	if limit > 0 {
+		values.Set("limit", strconv.Itoa(limit))
+	}
+	path := "/recent"
+	if len(values) > 0 {
+		path += "?" + values.Encode()
+	}
+	var items []RecentItem
+	err := c.do(ctx, http.MethodGet, path, nil, &items)
+	return items, err
+}
+
+// StartHealthCheck checks every link under path, or all links when path is
+// empty, and returns the job tracking the run.
+func (c *Client) StartHealthCheck(ctx context.Context, path []string) (HealthJob, error) {
+	var job HealthJob
+	err := c.do(ctx, http.MethodPost, "/health/check", map[string]any{"path": path}, &job)
+	return job, err
+}
+
+func (c *Client) HealthJob(ctx context.Context, id string) (HealthJob, error) {
+	var job HealthJob
+	err := c.do(ctx, http.MethodGet, "/health/jobs/"+url.PathEscape(id), nil, &job)
+	return job, err
+}
+
+func (c *Client) ApplyRedirects(ctx context.Context) (ApplyRedirectsResult, error) {
+	var result ApplyRedirectsResult
+	err := c.do(ctx, http.MethodPost, "/health/apply-redirects", nil, &result)
+	return result, err
+}
+
+func (c *Client) DeadLinkReport(ctx context.Context, includeDegraded bool) (DeadLinkReport, error) {
+	path := "/reports/dead-links"
+	if includeDegraded {
+		path += "?include=degraded"
+	}
+	var report DeadLinkReport
+	err := c.do(ctx, http.MethodGet, path, nil, &report)
+	return report, err
+}
+
+func (c *Client) DeadLinkAction(ctx context.Context, request DeadLinkActionRequest) ([]DeadLinkActionResult, error) {
+	var results []DeadLinkActionResult
+	err := c.do(ctx, http.MethodPost, "/reports/dead-links/actions", request, &results)
+	return results, err
+}
+
+func (c *Client) ListBookmarks(ctx context.Context) ([]BookmarkCategory, error) {
+	var categories []BookmarkCategory
+	err := c.do(ctx, http.MethodGet, "/bookmarks", nil, &categories)
+	return categories, err
+}
+
+func (c *Client) CreateBookmark(ctx context.Context, input BookmarkInput) (BookmarkLink, error) {
+	var bookmark BookmarkLink
+	err := c.do(ctx, http.MethodPost, "/bookmarks", input, &bookmark)
+	return bookmark, err
+}
+
+func (c *Client) UpdateBookmark(ctx context.Context, id string, input BookmarkInput) (BookmarkLink, error) {
+	var bookmark BookmarkLink
+	err := c.do(ctx, http.MethodPut, "/bookmarks/"+url.PathEscape(id), input, &bookmark)
+	return bookmark, err
+}
+
+func (c *Client) DeleteBookmark(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/bookmarks/"+url.PathEscape(id), nil, nil)
+}
+
+func (c *Client) ReorderBookmarks(ctx context.Context, move BookmarkMove) error {
+	return c.do(ctx, http.MethodPost, "/bookmarks/reorder", move, nil)
+}
+
+// BookmarkChanges pulls the bookmark changes after revision since. A since
+// of 0 returns a snapshot of every bookmark.
+func (c *Client) BookmarkChanges(ctx context.Context, since int64) (BookmarkSync, error) {
+	var result BookmarkSync
+	query := url.Values{"since": {strconv.FormatInt(since, 10)}}
+	err := c.do(ctx, http.MethodGet, "/bookmarks/sync?"+query.Encode(), nil, &result)
+	return result, err
+}
+
+// SyncBookmarks pushes changes, which the server applies when they are newer
+// than its last write for the bookmark, and pulls the changes after
+// request.Since.
+func (c *Client) SyncBookmarks(ctx context.Context, request BookmarkSyncRequest) (BookmarkSync, error) {
+	var result BookmarkSync
+	err := c.do(ctx, http.MethodPost, "/bookmarks/sync", request, &result)
+	return result, err
+}
+
+func (c *Client) ListBookmarkFolders(ctx context.Context) ([]BookmarkFolderInfo, error) {
+	var folders []BookmarkFolderInfo
+	err := c.do(ctx, http.MethodGet, "/bookmarks/folders", nil, &folders)
+	return folders, err
+}
+
+func (c *Client) CreateBookmarkFolder(ctx context.Context, input BookmarkFolderInput) (BookmarkFolderInfo, error) {
+	var folder BookmarkFolderInfo
+	err := c.do(ctx, http.MethodPost, "/bookmarks/folders", input, &folder)
+	return folder, err
+}
+
+func (c *Client) UpdateBookmarkFolder(ctx context.Context, path string, patch BookmarkFolderPatch) (BookmarkFolderInfo, error) {
+	var folder BookmarkFolderInfo
+	err := c.do(ctx, http.MethodPatch, "/bookmarks/folders/"+escapeFolderPath(path), patch, &folder)
+	return folder, err
+}
+
+// DeleteBookmarkFolder deletes a category or folder. Mode is "cascade" to
+// delete its contents, "move" to hand them to the parent, or "" to only
+// delete it when empty.
+func (c *Client) DeleteBookmarkFolder(ctx context.Context, path, mode string) error {
+	target := "/bookmarks/folders/" + escapeFolderPath(path)
+	if mode != "" {
+		target += "?" + url.Values{"mode": {mode}}.Encode()
+	}
+	return c.do(ctx, http.MethodDelete, target, nil, nil)
+}
+
+// escapeFolderPath escapes each segment of a slash separated folder path.
+func escapeFolderPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// DuplicateBookmarks groups bookmarks whose URLs match after normalisation,
+// ignoring the scheme and a leading www.
+func (c *Client) DuplicateBookmarks(ctx context.Context) ([]BookmarkDuplicateGroup, error) {
+	var groups []BookmarkDuplicateGroup
+	err := c.do(ctx, http.MethodGet, "/bookmarks/duplicates", nil, &groups)
+	return groups, err
+}
+
+// MergeBookmarks folds the bookmarks in ids into the one with ID keep and
+// deletes them, returning the kept bookmark.
+func (c *Client) MergeBookmarks(ctx context.Context, keep string, ids []string) (BookmarkLink, error) {
+	var bookmark BookmarkLink
+	err := c.do(ctx, http.MethodPost, "/bookmarks/merge", MergeRequest{Keep: keep, IDs: ids}, &bookmark)
+	return bookmark, err
+}
+
+func (c *Client) ExportBookmarks(ctx context.Context) (BookmarkConfig, error) {
+	var config BookmarkConfig
+	err := c.do(ctx, http.MethodGet, "/bookmarks/export", nil, &config)
+	return config, err
+}
+
+func (c *Client) ImportBookmarks(ctx context.Context, config BookmarkConfig, mode string) error {
+	body := map[string]any{"bookmarks": config.Bookmarks, "mode": mode}
+	return c.do(ctx, http.MethodPost, "/bookmarks/import", body, nil)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	resp, err := c.send(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// send performs a request and turns non-2xx responses into an *Error. The
+// caller closes the body of a successful response.
+func (c *Client) send(ctx context.Context, method, path string, body any) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, decodeError(resp)
+	}
+	return resp, nil
+}
+
+func decodeError(resp *http.Response) error {
+	apiErr := &Error{StatusCode: resp.StatusCode, Message: resp.Status}
+	var envelope struct {
+		Error struct {
+			Code      string `json:"code"`
+			Message   string `json:"message"`
+			RequestID string `json:"requestId"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err == nil && envelope.Error.Code != "" {
+		apiErr.Code = envelope.Error.Code
+		apiErr.Message = envelope.Error.Message
+		apiErr.RequestID = envelope.Error.RequestID
+	}
+	return apiErr
+}
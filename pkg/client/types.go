@@ -0,0 +1,359 @@
+package client
+
+import "time"
+
+type Link struct {
+	ID            string         `json:"id"`
+	URL           string         `json:"url"`
+	Name          string         `json:"name,omitempty"`
+	Description   string         `json:"description,omitempty"`
+	Path          []string       `json:"path"`
+	Tags          []string       `json:"tags,omitempty"`
+	Health        Health         `json:"health"`
+	LastChecked   time.Time      `json:"lastChecked"`
+	HealthHistory []HealthRecord `json:"healthHistory,omitempty"`
+	CreatedAt     time.Time      `json:"createdAt"`
+	UpdatedAt     time.Time      `json:"updatedAt"`
+	ReadState     string         `json:"readState,omitempty"`
+	Progress      int            `json:"progress,omitempty"`
+	Archived      bool           `json:"archived,omitempty"`
+	Starred       bool           `json:"starred,omitempty"`
+	QueuedAt      time.Time      `json:"queuedAt,omitzero"`
+	ReadAt        time.Time      `json:"readAt,omitzero"`
+}
+
+// ReadingStatePatch changes only the fields that are set. An empty ReadState
+// removes a link from the queue.
+type ReadingStatePatch struct {
+	ReadState *string `json:"readState,omitempty"`
+	Progress  *int    `json:"progress,omitempty"`
+	Archived  *bool   `json:"archived,omitempty"`
+	Starred   *bool   `json:"starred,omitempty"`
+}
+
+// LinkOperation is one step of BulkLinks: "delete", "move" to Path, or
+// "state" to apply State.
+type LinkOperation struct {
+	Op    string             `json:"op"`
+	ID    string             `json:"id"`
+	Path  []string           `json:"path,omitempty"`
+	State *ReadingStatePatch `json:"state,omitempty"`
+	Tags  []string           `json:"tags,omitempty"`
+}
+
+type LinkOperationResult struct {
+	Index  int    `json:"index"`
+	Op     string `json:"op"`
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Code   string `json:"code,omitempty"`
+	Error  string `json:"error,omitempty"`
+	Link   *Link  `json:"link,omitempty"`
+}
+
+type BulkResult struct {
+	Atomic     bool                  `json:"atomic"`
+	Applied    int                   `json:"applied"`
+	Failed     int                   `json:"failed"`
+	RolledBack bool                  `json:"rolledBack"`
+	Results    []LinkOperationResult `json:"results"`
+}
+
+type DuplicateGroup struct {
+	Key   string `json:"key"`
+	Links []Link `json:"links"`
+}
+
+type MergeRequest struct {
+	Keep string   `json:"keep"`
+	IDs  []string `json:"ids"`
+}
+
+type LinkInput struct {
+	URL         string   `json:"url"`
+	Name        string   `json:"name,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Path        []string `json:"path,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+type Health struct {
+	Status           string     `json:"status"`
+	State            string     `json:"state,omitempty"`
+	StatusCode       int        `json:"statusCode,omitempty"`
+	Method           string     `json:"method,omitempty"`
+	Attempts         int        `json:"attempts,omitempty"`
+	Error            string     `json:"error,omitempty"`
+	FinalURL         string     `json:"finalUrl,omitempty"`
+	Redirects        []Redirect `json:"redirects,omitempty"`
+	MovedPermanently bool       `json:"movedPermanently,omitempty"`
+}
+
+type Redirect struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"statusCode"`
+}
+
+type HealthRecord struct {
+	Status     string    `json:"status"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	CheckedAt  time.Time `json:"checkedAt"`
+}
+
+type LinkHealth struct {
+	ID          string         `json:"id"`
+	URL         string         `json:"url"`
+	Health      Health         `json:"health"`
+	LastChecked time.Time      `json:"lastChecked"`
+	History     []HealthRecord `json:"history"`
+}
+
+type Category struct {
+	Name string   `json:"name"`
+	Path []string `json:"path"`
+	CategoryMeta
+	Links            int                  `json:"links"`
+	Health           CategoryHealth       `json:"health"`
+	Categories       map[string]*Category `json:"categories,omitempty"`
+	SortedCategories []string             `json:"sortedCategories,omitempty"`
+}
+
+type CategoryMeta struct {
+	Description string `json:"description,omitempty"`
+	Icon        string `json:"icon,omitempty"`
+	Color       string `json:"color,omitempty"`
+	Order       int    `json:"order,omitempty"`
+	Pinned      bool   `json:"pinned,omitempty"`
+	Collapsed   bool   `json:"collapsed,omitempty"`
+}
+
+type CategoryHealth struct {
+	Healthy  int `json:"healthy"`
+	Degraded int `json:"degraded"`
+	Dead     int `json:"dead"`
+	Unknown  int `json:"unknown"`
+}
+
+type CategoryRename struct {
+	Path   string `json:"path"`
+	Name   string `json:"name"`
+	Merge  bool   `json:"merge,omitempty"`
+	DryRun bool   `json:"dryRun,omitempty"`
+}
+
+// CategoryMove moves Path under Parent; an empty Parent means the top level.
+type CategoryMove struct {
+	Path   string `json:"path"`
+	Parent string `json:"parent,omitempty"`
+	Merge  bool   `json:"merge,omitempty"`
+	DryRun bool   `json:"dryRun,omitempty"`
+}
+
+type PathChange struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Affected int    `json:"affected"`
+	Merged   bool   `json:"merged"`
+	DryRun   bool   `json:"dryRun"`
+}
+
+type HealthJob struct {
+	ID         string    `json:"id"`
+	Status     string    `json:"status"`
+	Path       []string  `json:"path,omitempty"`
+	LinkIDs    []string  `json:"linkIds,omitempty"`
+	Total      int       `json:"total"`
+	Checked    int       `json:"checked"`
+	Healthy    int       `json:"healthy"`
+	Unhealthy  int       `json:"unhealthy"`
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt,omitzero"`
+}
+
+type ApplyRedirectsResult struct {
+	Applied []Link `json:"applied"`
+	Skipped []struct {
+		ID    string `json:"id"`
+		URL   string `json:"url"`
+		Error string `json:"error"`
+	} `json:"skipped"`
+}
+
+type DeadLinkReport struct {
+	GeneratedAt time.Time      `json:"generatedAt"`
+	Total       int            `json:"total"`
+	ByType      map[string]int `json:"byType"`
+	Hosts       []DeadLinkHost `json:"hosts"`
+}
+
+type DeadLinkHost struct {
+	Host   string          `json:"host"`
+	Total  int             `json:"total"`
+	Groups []DeadLinkGroup `json:"groups"`
+}
+
+type DeadLinkGroup struct {
+	Type  string `json:"type"`
+	Links []Link `json:"links"`
+}
+
+type DeadLinkActionRequest struct {
+	Action          string   `json:"action"`
+	IDs             []string `json:"ids,omitempty"`
+	Path            []string `json:"path,omitempty"`
+	IncludeDegraded bool     `json:"includeDegraded,omitempty"`
+}
+
+type DeadLinkActionResult struct {
+	ID    string `json:"id"`
+	URL   string `json:"url,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+type BookmarkConfig struct {
+	Bookmarks []BookmarkCategory `json:"bookmarks"`
+}
+
+type BookmarkCategory struct {
+	Category string           `json:"category"`
+	Color    string           `json:"color,omitempty"`
+	Icon     string           `json:"icon,omitempty"`
+	Folded   bool             `json:"folded,omitempty"`
+	Pinned   bool             `json:"pinned,omitempty"`
+	Links    []BookmarkLink   `json:"links,omitempty"`
+	Folders  []BookmarkFolder `json:"folders,omitempty"`
+}
+
+type BookmarkFolder struct {
+	Name    string           `json:"name"`
+	Color   string           `json:"color,omitempty"`
+	Icon    string           `json:"icon,omitempty"`
+	Folded  bool             `json:"folded,omitempty"`
+	Pinned  bool             `json:"pinned,omitempty"`
+	Links   []BookmarkLink   `json:"links,omitempty"`
+	Folders []BookmarkFolder `json:"folders,omitempty"`
+}
+
+type BookmarkLink struct {
+	ID        string    `json:"id,omitempty"`
+	Name      string    `json:"name"`
+	URL       string    `json:"url"`
+	Icon      string    `json:"icon,omitempty"`
+	Color     string    `json:"color,omitempty"`
+	CreatedAt time.Time `json:"createdAt,omitzero"`
+	UpdatedAt time.Time `json:"updatedAt,omitzero"`
+}
+
+type BookmarkDuplicate struct {
+	BookmarkLink
+	Folder string `json:"folder"`
+}
+
+type BookmarkDuplicateGroup struct {
+	Key       string              `json:"key"`
+	Bookmarks []BookmarkDuplicate `json:"bookmarks"`
+}
+
+type BookmarkInput struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Icon   string `json:"icon,omitempty"`
+	Color  string `json:"color,omitempty"`
+	Folder string `json:"folder,omitempty"`
+}
+
+type RecentItem struct {
+	Type      string    `json:"type"`
+	ID        string    `json:"id"`
+	Name      string    `json:"name,omitempty"`
+	URL       string    `json:"url"`
+	Path      []string  `json:"path"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type QueueStats struct {
+	Unread   int        `json:"unread"`
+	Reading  int        `json:"reading"`
+	Read     int        `json:"read"`
+	Starred  int        `json:"starred"`
+	Archived int        `json:"archived"`
+	Trend    []QueueDay `json:"trend"`
+}
+
+type QueueDay struct {
+	Date     string `json:"date"`
+	Added    int    `json:"added"`
+	Finished int    `json:"finished"`
+	Size     int    `json:"size"`
+}
+
+// BookmarkMove moves the bookmark ID or the category or folder at Path next
+// to a sibling (Before or After) or to Index within Folder.
+type BookmarkFolderInfo struct {
+	Path      string `json:"path"`
+	Name      string `json:"name"`
+	Color     string `json:"color,omitempty"`
+	Icon      string `json:"icon,omitempty"`
+	Folded    bool   `json:"folded,omitempty"`
+	Pinned    bool   `json:"pinned,omitempty"`
+	Bookmarks int    `json:"bookmarks"`
+	Total     int    `json:"total"`
+}
+
+// BookmarkFolderInput creates a category or folder. A nil Pinned pins it.
+type BookmarkFolderInput struct {
+	Path   string `json:"path"`
+	Color  string `json:"color,omitempty"`
+	Icon   string `json:"icon,omitempty"`
+	Folded bool   `json:"folded,omitempty"`
+	Pinned *bool  `json:"pinned,omitempty"`
+}
+
+// BookmarkFolderPatch changes only the fields that are set. Parent "" makes
+// the container a category.
+type BookmarkFolderPatch struct {
+	Name   *string `json:"name,omitempty"`
+	Parent *string `json:"parent,omitempty"`
+	Color  *string `json:"color,omitempty"`
+	Icon   *string `json:"icon,omitempty"`
+	Folded *bool   `json:"folded,omitempty"`
+	Pinned *bool   `json:"pinned,omitempty"`
+}
+
+type BookmarkChange struct {
+	Revision  int64         `json:"revision,omitempty"`
+	Op        string        `json:"op"`
+	ID        string        `json:"id"`
+	Bookmark  *BookmarkLink `json:"bookmark,omitempty"`
+	Folder    string        `json:"folder,omitempty"`
+	Index     *int          `json:"index,omitempty"`
+	UpdatedAt time.Time     `json:"updatedAt"`
+}
+
+type BookmarkSyncRequest struct {
+	Since   int64            `json:"since"`
+	Changes []BookmarkChange `json:"changes,omitempty"`
+}
+
+type BookmarkSyncResult struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+type BookmarkSync struct {
+	Revision int64                `json:"revision"`
+	Reset    bool                 `json:"reset"`
+	Changes  []BookmarkChange     `json:"changes"`
+	Results  []BookmarkSyncResult `json:"results,omitempty"`
+}
+
+type BookmarkMove struct {
+	ID     string `json:"id,omitempty"`
+	Path   string `json:"path,omitempty"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+	Folder string `json:"folder,omitempty"`
+	Index  *int   `json:"index,omitempty"`
+}